@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/apitesting"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+
+	oappsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	oapps "github.com/openshift/origin/pkg/apps/apis/apps"
+	build "github.com/openshift/origin/pkg/build/apis/build"
+	image "github.com/openshift/origin/pkg/image/apis/image"
+)
+
+// openAPISchemaTargets pairs each covered internal GVK, and the external
+// version it is encoded to, with the bundled OpenAPI v2 schema describing
+// that wire format. This intentionally covers a smaller set than
+// TestRoundTripTypes: it is the set of types this chunk ships a
+// hand-authored schema for under testdata/openapi/, not every registered
+// Kind.
+var openAPISchemaTargets = []struct {
+	name       string
+	gvk        schema.GroupVersionKind
+	externalGV schema.GroupVersion
+	schemaFile string
+}{
+	{"DeploymentConfig", oapps.SchemeGroupVersion.WithKind("DeploymentConfig"), oappsv1.SchemeGroupVersion, "deploymentconfig.swagger.json"},
+	{"BuildConfig", build.SchemeGroupVersion.WithKind("BuildConfig"), buildv1.SchemeGroupVersion, "buildconfig.swagger.json"},
+	{"ImageStream", image.SchemeGroupVersion.WithKind("ImageStream"), imagev1.SchemeGroupVersion, "imagestream.swagger.json"},
+}
+
+// loadOpenAPISchema reads and parses one of the bundled schema files under
+// pkg/api/testdata/openapi/.
+func loadOpenAPISchema(t *testing.T, file string) *spec.Schema {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "openapi", file))
+	if err != nil {
+		t.Fatalf("failed to read schema %s: %v", file, err)
+	}
+	var s spec.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("failed to parse schema %s: %v", file, err)
+	}
+	return &s
+}
+
+// TestFuzzedObjectsMatchOpenAPISchema fuzzes an instance of every Kind in
+// openAPISchemaTargets, encodes it to JSON in its external version, and
+// validates the result against the Kind's bundled OpenAPI schema; nothing is
+// decoded back. This catches the case where a custom fuzz
+// func (the DeploymentConfig strategy params logic, the ImageStream tag map
+// manipulation, the BuildConfig strategy union) produces a payload that
+// round-trips cleanly through Go but the apiserver's OpenAPI validation
+// layer would reject.
+func TestFuzzedObjectsMatchOpenAPISchema(t *testing.T) {
+	fuzzer := originFuzzer(t, 1)
+
+	for _, target := range openAPISchemaTargets {
+		target := target
+		t.Run(target.name, func(t *testing.T) {
+			sch := loadOpenAPISchema(t, target.schemaFile)
+			validator := validate.NewSchemaValidator(sch, nil, "", strfmt.Default)
+			codec := apitesting.TestCodec(legacyscheme.Codecs, target.externalGV)
+
+			for i := 0; i < fuzzIters; i++ {
+				obj, err := legacyscheme.Scheme.New(target.gvk)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fuzzer.Fuzz(obj)
+
+				data, err := runtime.Encode(codec, obj)
+				if err != nil {
+					t.Fatalf("failed to encode fuzzed object: %v", err)
+				}
+
+				var generic interface{}
+				if err := json.Unmarshal(data, &generic); err != nil {
+					t.Fatalf("failed to unmarshal encoded object: %v", err)
+				}
+
+				if result := validator.Validate(generic); result.HasErrors() {
+					t.Errorf("fuzzed %s failed OpenAPI schema validation: %v", target.name, result.AsError())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package fuzzer holds fuzz helpers shared by the authorization API's
+// round-trip tests, so the subject-normalization rules fuzzed objects must
+// obey only need to be written once.
+package fuzzer
+
+import (
+	"fmt"
+
+	"github.com/google/gofuzz"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/core/validation"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+	uservalidation "github.com/openshift/origin/pkg/user/apis/user/validation"
+)
+
+// FuzzSubjects fuzzes subjects in place and normalizes each one to a kind
+// and name/namespace combination that validateRoleBindingSubject (in the
+// sibling validation package) accepts, then returns subjects for
+// convenience. It is the single place RoleBinding and ClusterRoleBinding
+// fuzzing logic should normalize subjects, so the two no longer drift out of
+// sync with each other or with real subject validation as new subject kinds
+// are added.
+func FuzzSubjects(subjects []kapi.ObjectReference, c fuzz.Continue) []kapi.ObjectReference {
+	kinds := []string{
+		authorizationapi.UserKind,
+		authorizationapi.SystemUserKind,
+		authorizationapi.GroupKind,
+		authorizationapi.SystemGroupKind,
+		authorizationapi.ServiceAccountKind,
+	}
+
+	for i := range subjects {
+		subjects[i].Kind = kinds[c.Intn(len(kinds))]
+		switch subjects[i].Kind {
+		case authorizationapi.UserKind:
+			subjects[i].Namespace = ""
+			if len(uservalidation.ValidateUserName(subjects[i].Name, false)) != 0 {
+				subjects[i].Name = fmt.Sprintf("validusername%d", i)
+			}
+
+		case authorizationapi.GroupKind:
+			subjects[i].Namespace = ""
+			if len(uservalidation.ValidateGroupName(subjects[i].Name, false)) != 0 {
+				subjects[i].Name = fmt.Sprintf("validgroupname%d", i)
+			}
+
+		case authorizationapi.ServiceAccountKind:
+			if len(validation.ValidateNamespaceName(subjects[i].Namespace, false)) != 0 {
+				subjects[i].Namespace = fmt.Sprintf("sanamespacehere%d", i)
+			}
+			if len(validation.ValidateServiceAccountName(subjects[i].Name, false)) != 0 {
+				subjects[i].Name = fmt.Sprintf("sanamehere%d", i)
+			}
+
+		case authorizationapi.SystemUserKind, authorizationapi.SystemGroupKind:
+			subjects[i].Namespace = ""
+			subjects[i].Name = ":" + subjects[i].Name
+		}
+
+		subjects[i].UID = ""
+		subjects[i].APIVersion = ""
+		subjects[i].ResourceVersion = ""
+		subjects[i].FieldPath = ""
+	}
+
+	return subjects
+}
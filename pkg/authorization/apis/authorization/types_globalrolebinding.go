@@ -0,0 +1,32 @@
+package authorization
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// GlobalRoleBinding references a ClusterRole and requests that it be
+// propagated, as a ClusterRoleBinding, to every cluster registered in the
+// member Cluster registry. The global authorization controller reconciles
+// drift between this object and the ClusterRoleBindings it owns in each
+// member cluster.
+type GlobalRoleBinding struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	// Subjects hold object references to authorize with this rule
+	Subjects []kapi.ObjectReference
+
+	// RoleRef can only reference a ClusterRole in the global control plane.
+	// It is copied verbatim into each propagated ClusterRoleBinding.
+	RoleRef kapi.ObjectReference
+}
+
+// GlobalRoleBindingList is a collection of GlobalRoleBindings
+type GlobalRoleBindingList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []GlobalRoleBinding
+}
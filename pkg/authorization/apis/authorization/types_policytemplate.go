@@ -0,0 +1,58 @@
+package authorization
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+	rbac "k8s.io/kubernetes/pkg/apis/rbac"
+)
+
+// PolicyTemplateVersionAnnotation is set on every namespace a PolicyTemplate
+// has materialized objects into. Its value is a comma separated list of
+// "templateName=resourceVersion" pairs, one per PolicyTemplate applied,
+// recording the ResourceVersion of each that was last applied. The
+// controller compares this annotation against each current PolicyTemplate
+// before re-materializing, so that a template already current in a namespace
+// is skipped.
+const PolicyTemplateVersionAnnotation = "template.openshift.io/version"
+
+// PolicyTemplate describes a set of Roles and RoleBindings to materialize
+// into every namespace backing a Project, replacing a one-off hardcoded set
+// of bootstrap service-account bindings with a configurable, versioned
+// template. Roles and RoleBindings created from a PolicyTemplate are owned,
+// via ownerReferences, by the Namespace backing the Project they were
+// materialized into, and are
+// re-materialized whenever the PolicyTemplate is updated.
+type PolicyTemplate struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	RoleTemplates        []RoleTemplate
+	RoleBindingTemplates []RoleBindingTemplate
+}
+
+// RoleTemplate is materialized into a Role in the target namespace.
+type RoleTemplate struct {
+	// Name is substituted the same as any other template string, allowing
+	// per-project role names such as "${PROJECT}-viewer".
+	Name  string
+	Rules []rbac.PolicyRule
+}
+
+// RoleBindingTemplate is materialized into a RoleBinding in the target
+// namespace. Subjects and RoleRef.Name support the ${PROJECT}, ${REQUESTER},
+// and ${SA:<name>} substitution markers; ${SA:<name>} expands to a
+// ServiceAccount subject named <name> in the materialized namespace.
+type RoleBindingTemplate struct {
+	Name     string
+	Subjects []kapi.ObjectReference
+	RoleRef  kapi.ObjectReference
+}
+
+// PolicyTemplateList is a collection of PolicyTemplates
+type PolicyTemplateList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []PolicyTemplate
+}
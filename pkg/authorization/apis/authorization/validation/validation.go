@@ -0,0 +1,67 @@
+// Package validation holds validation logic for the authorization API types.
+package validation
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/core/validation"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+	uservalidation "github.com/openshift/origin/pkg/user/apis/user/validation"
+)
+
+// validateRoleBindingSubject validates a single RoleBinding or
+// ClusterRoleBinding subject against the same name/namespace rules the
+// fuzzer package's FuzzSubjects helper normalizes fuzzed subjects to, so the
+// two cannot silently drift apart as new subject kinds are added.
+func validateRoleBindingSubject(subject kapi.ObjectReference, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch subject.Kind {
+	case authorizationapi.UserKind:
+		if len(subject.Namespace) != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), subject.Namespace, "may not be specified for a user subject"))
+		}
+		for _, msg := range uservalidation.ValidateUserName(subject.Name, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), subject.Name, msg))
+		}
+
+	case authorizationapi.GroupKind:
+		if len(subject.Namespace) != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), subject.Namespace, "may not be specified for a group subject"))
+		}
+		for _, msg := range uservalidation.ValidateGroupName(subject.Name, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), subject.Name, msg))
+		}
+
+	case authorizationapi.ServiceAccountKind:
+		for _, msg := range validation.ValidateNamespaceName(subject.Namespace, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), subject.Namespace, msg))
+		}
+		for _, msg := range validation.ValidateServiceAccountName(subject.Name, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), subject.Name, msg))
+		}
+
+	case authorizationapi.SystemUserKind, authorizationapi.SystemGroupKind:
+		if len(subject.Namespace) != 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), subject.Namespace, fmt.Sprintf("may not be specified for a %s subject", subject.Kind)))
+		}
+		if len(subject.Name) == 0 || subject.Name[0] != ':' {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), subject.Name, fmt.Sprintf("%s subject names must begin with \":\"", subject.Kind)))
+		}
+
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("kind"), subject.Kind, []string{
+			authorizationapi.UserKind,
+			authorizationapi.SystemUserKind,
+			authorizationapi.GroupKind,
+			authorizationapi.SystemGroupKind,
+			authorizationapi.ServiceAccountKind,
+		}))
+	}
+
+	return allErrs
+}
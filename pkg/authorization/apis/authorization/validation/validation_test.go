@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/gofuzz"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	"github.com/openshift/origin/pkg/authorization/apis/authorization/fuzzer"
+)
+
+// subjectsHolder lets FuzzSubjects, which takes a fuzz.Continue, be invoked
+// through the normal Funcs registration mechanism rather than by
+// constructing a fuzz.Continue by hand.
+type subjectsHolder struct {
+	Subjects []kapi.ObjectReference
+}
+
+// TestFuzzedSubjectsPassValidation cross-checks fuzzer.FuzzSubjects against
+// validateRoleBindingSubject: every subject the fuzzer produces must be one
+// real validation accepts. This is the gate that catches the two silently
+// drifting apart again, e.g. if a new subject kind is added to one without
+// the other.
+func TestFuzzedSubjectsPassValidation(t *testing.T) {
+	f := fuzz.New().Funcs(
+		func(h *subjectsHolder, c fuzz.Continue) {
+			h.Subjects = make([]kapi.ObjectReference, 5)
+			for i := range h.Subjects {
+				c.Fuzz(&h.Subjects[i])
+			}
+			h.Subjects = fuzzer.FuzzSubjects(h.Subjects, c)
+		},
+	)
+	f.RandSource(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		var holder subjectsHolder
+		f.Fuzz(&holder)
+
+		for _, subject := range holder.Subjects {
+			if errs := validateRoleBindingSubject(subject, field.NewPath("subjects")); len(errs) != 0 {
+				t.Errorf("fuzzed subject %#v failed validation: %v", subject, errs)
+			}
+		}
+	}
+}
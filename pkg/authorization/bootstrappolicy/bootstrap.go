@@ -0,0 +1,105 @@
+package bootstrappolicy
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+)
+
+// PostStartHookName is the name the RBAC REST storage provider registers
+// this reconciliation under, so it runs once on every server start.
+const PostStartHookName = "authorization.openshift.io-bootstrapclusterroles"
+
+// PostStartHook returns the genericapiserver.PostStartHookFunc a server
+// should register under PostStartHookName via AddPostStartHookOrDie, so
+// EnsureBootstrapPolicy actually runs once on every server start rather than
+// only from a test. newClient builds the privileged RBAC client to
+// reconcile with, deferred until the hook fires since the loopback client
+// config a PostStartHookContext carries is not available any earlier.
+func PostStartHook(newClient func(hookContext genericapiserver.PostStartHookContext) (rbacv1client.RbacV1Interface, error)) genericapiserver.PostStartHookFunc {
+	return func(hookContext genericapiserver.PostStartHookContext) error {
+		client, err := newClient(hookContext)
+		if err != nil {
+			return err
+		}
+		return EnsureBootstrapPolicy(client)
+	}
+}
+
+// EnsureBootstrapPolicy creates any default ClusterRole or ClusterRoleBinding
+// that does not yet exist, and reconciles every one that does against its
+// canonical definition, adding rules/subjects a newer server version expects
+// without discarding anything a cluster-admin added by hand. client is
+// expected to authenticate as an identity a rulevalidation.EscalationPolicy
+// treats as privileged (e.g. a member of system:masters), since writing
+// cluster-admin's own rules would otherwise trip the very escalation check
+// those rules are used to enforce.
+func EnsureBootstrapPolicy(client rbacv1client.RbacV1Interface) error {
+	var errs []error
+	for _, expected := range ClusterRoles() {
+		if err := ensureClusterRole(client, expected); err != nil {
+			errs = append(errs, fmt.Errorf("cluster role %q: %v", expected.Name, err))
+		}
+	}
+	for _, expected := range ClusterRoleBindings() {
+		if err := ensureClusterRoleBinding(client, expected); err != nil {
+			errs = append(errs, fmt.Errorf("cluster role binding %q: %v", expected.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("bootstrap policy reconciliation failed: %v", errs)
+	}
+	return nil
+}
+
+func ensureClusterRole(client rbacv1client.RbacV1Interface, expected rbacv1.ClusterRole) error {
+	existing, err := client.ClusterRoles().Get(expected.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err := client.ClusterRoles().Create(&expected)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	reconciled, changed := reconcileClusterRole(existing, &expected)
+	if !changed {
+		return nil
+	}
+	_, err = client.ClusterRoles().Update(reconciled)
+	return err
+}
+
+func ensureClusterRoleBinding(client rbacv1client.RbacV1Interface, expected rbacv1.ClusterRoleBinding) error {
+	existing, err := client.ClusterRoleBindings().Get(expected.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err := client.ClusterRoleBindings().Create(&expected)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if roleRefChanged(existing.RoleRef, expected.RoleRef) {
+		// RoleRef is immutable on a binding; correcting drift here requires
+		// deleting it (preconditioned on its UID) and recreating it, the
+		// same pattern the globalrolebinding controller uses for the same
+		// reason. The two calls are not atomic.
+		if err := client.ClusterRoleBindings().Delete(expected.Name, metav1.NewPreconditionDeleteOptions(string(existing.UID))); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		_, err := client.ClusterRoleBindings().Create(&expected)
+		return err
+	}
+
+	reconciled, changed := reconcileClusterRoleBinding(existing, &expected)
+	if !changed {
+		return nil
+	}
+	_, err = client.ClusterRoleBindings().Update(reconciled)
+	return err
+}
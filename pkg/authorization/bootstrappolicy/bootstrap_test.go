@@ -0,0 +1,191 @@
+package bootstrappolicy
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/kubernetes/fake"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+)
+
+func TestEnsureBootstrapPolicyCreatesDefaults(t *testing.T) {
+	client := fake.NewSimpleClientset().RbacV1()
+
+	if err := EnsureBootstrapPolicy(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range ClusterRoles() {
+		cr, err := client.ClusterRoles().Get(expected.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected cluster role %q to be created: %v", expected.Name, err)
+		}
+		if len(cr.Rules) != len(expected.Rules) {
+			t.Errorf("expected cluster role %q to have %d rules, got %d", expected.Name, len(expected.Rules), len(cr.Rules))
+		}
+	}
+	for _, expected := range ClusterRoleBindings() {
+		crb, err := client.ClusterRoleBindings().Get(expected.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected cluster role binding %q to be created: %v", expected.Name, err)
+		}
+		if len(crb.Subjects) != len(expected.Subjects) {
+			t.Errorf("expected cluster role binding %q to have %d subjects, got %d", expected.Name, len(expected.Subjects), len(crb.Subjects))
+		}
+	}
+}
+
+func TestEnsureBootstrapPolicyRecreatesDeletedDefault(t *testing.T) {
+	client := fake.NewSimpleClientset().RbacV1()
+	if err := EnsureBootstrapPolicy(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := client.ClusterRoles().Delete(ClusterRoleClusterAdmin, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A second call models what the PostStartHook does on the next server
+	// start: it must notice cluster-admin is gone and recreate it.
+	if err := EnsureBootstrapPolicy(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cr, err := client.ClusterRoles().Get(ClusterRoleClusterAdmin, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected cluster-admin to be recreated: %v", err)
+	}
+	if len(cr.Rules) != 2 || cr.Rules[0].Verbs[0] != "*" {
+		t.Fatalf("expected cluster-admin to be recreated with its canonical rules, got %#v", cr.Rules)
+	}
+}
+
+func TestEnsureBootstrapPolicyPreservesHandAddedRules(t *testing.T) {
+	client := fake.NewSimpleClientset().RbacV1()
+	if err := EnsureBootstrapPolicy(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	existing, err := client.ClusterRoles().Get(ClusterRoleView, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing.Rules = append(existing.Rules, rbacv1.PolicyRule{APIGroups: []string{"example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get"}})
+	if _, err := client.ClusterRoles().Update(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EnsureBootstrapPolicy(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reconciled, err := client.ClusterRoles().Get(ClusterRoleView, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, rule := range reconciled.Rules {
+		if len(rule.Resources) > 0 && rule.Resources[0] == "widgets" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected the hand-added widgets rule to survive reconciliation, got %#v", reconciled.Rules)
+	}
+}
+
+func TestEnsureBootstrapPolicyAddsMissingRuleWithoutClobberingExtras(t *testing.T) {
+	client := fake.NewSimpleClientset().RbacV1()
+
+	// Seed an out-of-date view role: missing the "secrets" rule the
+	// canonical definition expects, but carrying a hand-added extra.
+	stale := rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleView},
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{"example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get"}},
+		},
+	}
+	if _, err := client.ClusterRoles().Create(&stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EnsureBootstrapPolicy(client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reconciled, err := client.ClusterRoles().Get(ClusterRoleView, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foundSecrets, foundWidgets := false, false
+	for _, rule := range reconciled.Rules {
+		for _, resource := range rule.Resources {
+			if resource == "secrets" {
+				foundSecrets = true
+			}
+			if resource == "widgets" {
+				foundWidgets = true
+			}
+		}
+	}
+	if !foundSecrets {
+		t.Errorf("expected the missing secrets rule to be added, got %#v", reconciled.Rules)
+	}
+	if !foundWidgets {
+		t.Errorf("expected the hand-added widgets rule to survive, got %#v", reconciled.Rules)
+	}
+}
+
+func TestEnsureBootstrapPolicyAutoUpdateOverwritesHandEdits(t *testing.T) {
+	client := fake.NewSimpleClientset().RbacV1()
+
+	autoUpdating := rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "auto-updating-role", Annotations: map[string]string{AutoUpdateAnnotation: "true"}},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}
+	if _, err := client.ClusterRoles().Create(&autoUpdating); err != nil {
+		t.Fatal(err)
+	}
+	existing, err := client.ClusterRoles().Get("auto-updating-role", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing.Rules = append(existing.Rules, rbacv1.PolicyRule{APIGroups: []string{"example.com"}, Resources: []string{"widgets"}, Verbs: []string{"get"}})
+	if _, err := client.ClusterRoles().Update(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	reconciled, changed := reconcileClusterRole(existing, &autoUpdating)
+	if !changed {
+		t.Fatal("expected autoupdate to report a change when a hand-added rule is present")
+	}
+	if len(reconciled.Rules) != 1 {
+		t.Fatalf("expected autoupdate to discard the hand-added rule, got %#v", reconciled.Rules)
+	}
+}
+
+// TestPostStartHookRunsEnsureBootstrapPolicy verifies PostStartHook's
+// genericapiserver.PostStartHookFunc actually calls EnsureBootstrapPolicy
+// with the client newClient builds from the hook context, the same wiring a
+// real AddPostStartHookOrDie(PostStartHookName, ...) registration would run
+// on every server start.
+func TestPostStartHookRunsEnsureBootstrapPolicy(t *testing.T) {
+	client := fake.NewSimpleClientset().RbacV1()
+
+	hook := PostStartHook(func(genericapiserver.PostStartHookContext) (rbacv1client.RbacV1Interface, error) {
+		return client, nil
+	})
+
+	if err := hook(genericapiserver.PostStartHookContext{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range ClusterRoles() {
+		if _, err := client.ClusterRoles().Get(expected.Name, metav1.GetOptions{}); err != nil {
+			t.Errorf("expected cluster role %q to be created by the hook: %v", expected.Name, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package bootstrappolicy defines the canonical set of default ClusterRoles
+// and ClusterRoleBindings every cluster must have, and reconciles them
+// against live state at server start so upgrades that add new default rules
+// or subjects do not require (and do not clobber) hand-applied
+// customizations. See EnsureBootstrapPolicy.
+package bootstrappolicy
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AutoUpdateAnnotation, when set to "true" on a default ClusterRole or
+// ClusterRoleBinding, causes reconciliation to fully overwrite Rules or
+// Subjects to match the canonical definition on every sync instead of only
+// adding whatever is missing. It is unset on the roles and bindings defined
+// in this package by default, so a cluster-admin who has hand-edited one of
+// them keeps their customization across upgrades; set it explicitly on an
+// object to opt it back into being forced to the canonical definition.
+const AutoUpdateAnnotation = "rbac.authorization.kubernetes.io/autoupdate"
+
+// Names of the default ClusterRoles this package bootstraps.
+const (
+	ClusterRoleAdmin        = "admin"
+	ClusterRoleEdit         = "edit"
+	ClusterRoleView         = "view"
+	ClusterRoleClusterAdmin = "cluster-admin"
+	ClusterRoleDiscovery    = "system:discovery"
+	ClusterRoleBasicUser    = "system:basic-user"
+)
+
+// Well-known groups the default ClusterRoleBindings bind to.
+const (
+	GroupMasters         = "system:masters"
+	GroupAuthenticated   = "system:authenticated"
+	GroupUnauthenticated = "system:unauthenticated"
+)
+
+// ClusterRoles returns the canonical set of default ClusterRoles every
+// cluster bootstraps with.
+func ClusterRoles() []rbacv1.ClusterRole {
+	return []rbacv1.ClusterRole{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleClusterAdmin},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+				{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleAdmin},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleEdit},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods", "services", "configmaps", "secrets"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleView},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods", "services", "configmaps", "secrets"}, Verbs: []string{"get", "list", "watch"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleDiscovery},
+			Rules: []rbacv1.PolicyRule{
+				{NonResourceURLs: []string{"/api", "/api/*", "/apis", "/apis/*", "/version", "/version/*", "/openapi", "/openapi/*"}, Verbs: []string{"get"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleBasicUser},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"selfsubjectaccessreviews", "selfsubjectrulesreviews"}, Verbs: []string{"create"}},
+			},
+		},
+	}
+}
+
+// ClusterRoleBindings returns the canonical set of default
+// ClusterRoleBindings binding the roles returned by ClusterRoles to their
+// well-known subjects.
+func ClusterRoleBindings() []rbacv1.ClusterRoleBinding {
+	return []rbacv1.ClusterRoleBinding{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleClusterAdmin},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: ClusterRoleClusterAdmin},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: GroupMasters},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleDiscovery},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: ClusterRoleDiscovery},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: GroupAuthenticated},
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: GroupUnauthenticated},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleBasicUser},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: ClusterRoleBasicUser},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: GroupAuthenticated},
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: GroupUnauthenticated},
+			},
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package bootstrappolicy
+
+import (
+	"reflect"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// reconcileClusterRole merges expected's Rules into existing: every rule in
+// expected not already present (by deep equality) on existing is appended,
+// and any extra rule a cluster-admin has added to existing is left alone. If
+// existing carries AutoUpdateAnnotation=true, existing.Rules is instead
+// replaced outright to match expected.Rules. The bool result reports whether
+// existing needed to change.
+func reconcileClusterRole(existing, expected *rbacv1.ClusterRole) (*rbacv1.ClusterRole, bool) {
+	if existing.Annotations[AutoUpdateAnnotation] == "true" {
+		if reflect.DeepEqual(existing.Rules, expected.Rules) {
+			return existing, false
+		}
+		updated := existing.DeepCopy()
+		updated.Rules = expected.Rules
+		return updated, true
+	}
+
+	updated := existing.DeepCopy()
+	changed := false
+	for _, rule := range expected.Rules {
+		if !containsRule(updated.Rules, rule) {
+			updated.Rules = append(updated.Rules, rule)
+			changed = true
+		}
+	}
+	return updated, changed
+}
+
+// reconcileClusterRoleBinding merges expected's Subjects into existing,
+// preserving any subject a cluster-admin has added, unless
+// AutoUpdateAnnotation=true on existing requests existing.Subjects be
+// replaced outright.
+func reconcileClusterRoleBinding(existing, expected *rbacv1.ClusterRoleBinding) (*rbacv1.ClusterRoleBinding, bool) {
+	if existing.Annotations[AutoUpdateAnnotation] == "true" {
+		if reflect.DeepEqual(existing.Subjects, expected.Subjects) {
+			return existing, false
+		}
+		updated := existing.DeepCopy()
+		updated.Subjects = expected.Subjects
+		return updated, true
+	}
+
+	updated := existing.DeepCopy()
+	changed := false
+	for _, subject := range expected.Subjects {
+		if !containsSubject(updated.Subjects, subject) {
+			updated.Subjects = append(updated.Subjects, subject)
+			changed = true
+		}
+	}
+	return updated, changed
+}
+
+func containsRule(rules []rbacv1.PolicyRule, rule rbacv1.PolicyRule) bool {
+	for _, r := range rules {
+		if reflect.DeepEqual(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSubject(subjects []rbacv1.Subject, subject rbacv1.Subject) bool {
+	for _, s := range subjects {
+		if reflect.DeepEqual(s, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func roleRefChanged(existing, expected rbacv1.RoleRef) bool {
+	return existing.APIGroup != expected.APIGroup || existing.Kind != expected.Kind || existing.Name != expected.Name
+}
@@ -0,0 +1,158 @@
+// Package clusterroleaggregation implements a controller that keeps an
+// aggregated ClusterRole's Rules in sync with the union of every ClusterRole
+// its AggregationRule selects, mirroring upstream RBAC's own
+// ClusterRoleAggregationController. Direct writes to Rules on an aggregated
+// ClusterRole are treated as drift and overwritten on the next sync.
+package clusterroleaggregation
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+// ClusterRoleLister lists every ClusterRole currently known, the set that
+// AggregationRule label selectors are evaluated against.
+type ClusterRoleLister func() ([]*rbacv1.ClusterRole, error)
+
+// Controller recomputes Rules for every ClusterRole with a non-nil
+// AggregationRule as the union of every other ClusterRole matching any of
+// its ClusterRoleSelectors.
+type Controller struct {
+	clusterRoles ClusterRoleLister
+	client       rbacv1client.ClusterRolesGetter
+
+	queue workqueue.RateLimitingInterface
+}
+
+// NewController builds a Controller.
+func NewController(clusterRoles ClusterRoleLister, client rbacv1client.ClusterRolesGetter) *Controller {
+	return &Controller{
+		clusterRoles: clusterRoles,
+		client:       client,
+		queue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "cluster-role-aggregation"),
+	}
+}
+
+// Enqueue requeues the named ClusterRole for reconciliation.
+func (c *Controller) Enqueue(name string) {
+	c.queue.Add(name)
+}
+
+// Run starts workers processing the queue until stopCh is closed.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting cluster role aggregation controller")
+	defer klog.Infof("Shutting down cluster role aggregation controller")
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(key.(string)); err != nil {
+		runtime.HandleError(fmt.Errorf("error aggregating cluster role %q: %v", key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) sync(name string) error {
+	all, err := c.clusterRoles()
+	if err != nil {
+		return err
+	}
+
+	var target *rbacv1.ClusterRole
+	for _, cr := range all {
+		if cr.Name == name {
+			target = cr
+			break
+		}
+	}
+	if target == nil || target.AggregationRule == nil {
+		// Deleted, or not an aggregated role; nothing to recompute.
+		return nil
+	}
+
+	// The aggregated role never contributes its own (possibly directly
+	// written) Rules, even when it matches one of its own selectors.
+	others := make([]*rbacv1.ClusterRole, 0, len(all))
+	for _, cr := range all {
+		if cr.Name != name {
+			others = append(others, cr)
+		}
+	}
+	desired, err := AggregatedRules(target.AggregationRule, others)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(target.Rules, desired) {
+		return nil
+	}
+
+	updated := target.DeepCopy()
+	updated.Rules = desired
+	_, err = c.client.ClusterRoles().Update(updated)
+	return err
+}
+
+// AggregatedRules computes the union of Rules from every ClusterRole in all
+// matching any of rule's ClusterRoleSelectors. Rules are deduplicated by
+// deep equality so that a ClusterRole matched by more than one selector does
+// not contribute its rules twice.
+func AggregatedRules(rule *rbacv1.AggregationRule, all []*rbacv1.ClusterRole) ([]rbacv1.PolicyRule, error) {
+	var rules []rbacv1.PolicyRule
+	for _, selector := range rule.ClusterRoleSelectors {
+		sel, err := metav1.LabelSelectorAsSelector(&selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range all {
+			if !sel.Matches(labels.Set(candidate.Labels)) {
+				continue
+			}
+			for _, r := range candidate.Rules {
+				if !containsRule(rules, r) {
+					rules = append(rules, r)
+				}
+			}
+		}
+	}
+	return rules, nil
+}
+
+func containsRule(rules []rbacv1.PolicyRule, rule rbacv1.PolicyRule) bool {
+	for _, r := range rules {
+		if reflect.DeepEqual(r, rule) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package clusterroleaggregation
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const labelKey = "rbac.example.com/aggregate-to-probe"
+
+func newTestController(clusterRoles []*rbacv1.ClusterRole) (*Controller, *fake.Clientset) {
+	clientset := fake.NewSimpleClientset()
+	for _, cr := range clusterRoles {
+		if _, err := clientset.RbacV1().ClusterRoles().Create(cr); err != nil {
+			panic(err)
+		}
+	}
+
+	controller := NewController(
+		func() ([]*rbacv1.ClusterRole, error) {
+			list, err := clientset.RbacV1().ClusterRoles().List(metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			out := make([]*rbacv1.ClusterRole, 0, len(list.Items))
+			for i := range list.Items {
+				out = append(out, &list.Items[i])
+			}
+			return out, nil
+		},
+		clientset.RbacV1(),
+	)
+	return controller, clientset
+}
+
+func TestSyncRecomputesAggregatedRules(t *testing.T) {
+	component1 := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "component-1", Labels: map[string]string{labelKey: "true"}},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}
+	component2 := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "component-2", Labels: map[string]string{labelKey: "true"}},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"get"}}},
+	}
+	aggregate := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "aggregate"},
+		AggregationRule: &rbacv1.AggregationRule{
+			ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{labelKey: "true"}}},
+		},
+	}
+
+	controller, clientset := newTestController([]*rbacv1.ClusterRole{component1, component2, aggregate})
+	if err := controller.sync(aggregate.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := clientset.RbacV1().ClusterRoles().Get(aggregate.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[string]bool{}
+	for _, rule := range updated.Rules {
+		for _, resource := range rule.Resources {
+			found[resource] = true
+		}
+	}
+	for _, want := range []string{"pods", "services"} {
+		if !found[want] {
+			t.Errorf("expected aggregated rules to include resource %q, got %#v", want, updated.Rules)
+		}
+	}
+}
+
+func TestSyncOverwritesDirectRuleWrites(t *testing.T) {
+	component := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "component", Labels: map[string]string{labelKey: "true"}},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}
+	aggregate := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "aggregate"},
+		AggregationRule: &rbacv1.AggregationRule{
+			ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{labelKey: "true"}}},
+		},
+		// A direct write smuggling in extra access the selectors don't grant.
+		Rules: []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+	}
+
+	controller, clientset := newTestController([]*rbacv1.ClusterRole{component, aggregate})
+	if err := controller.sync(aggregate.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := clientset.RbacV1().ClusterRoles().Get(aggregate.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Rules) != 1 || updated.Rules[0].Resources[0] != "pods" {
+		t.Fatalf("expected the direct rule write to be overwritten by the aggregated rules, got %#v", updated.Rules)
+	}
+}
+
+func TestSyncIgnoresNonAggregatedClusterRole(t *testing.T) {
+	plain := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "plain"},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}
+	controller, clientset := newTestController([]*rbacv1.ClusterRole{plain})
+	if err := controller.sync(plain.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := clientset.RbacV1().ClusterRoles().Get(plain.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Rules) != 1 || updated.Rules[0].Resources[0] != "pods" {
+		t.Fatalf("expected a non-aggregated cluster role to be left untouched, got %#v", updated.Rules)
+	}
+}
@@ -0,0 +1,270 @@
+// Package globalrolebinding implements a controller that fans a
+// GlobalRoleBinding out to every cluster registered in the member Cluster
+// registry, materializing (and continuously reconciling) a ClusterRoleBinding
+// of the same name in each one.
+package globalrolebinding
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+)
+
+// ManagedByGlobalLabel is stamped onto every ClusterRoleBinding a
+// GlobalRoleBinding propagates so that member-cluster orphans (bindings whose
+// owning GlobalRoleBinding or cluster membership has gone away) can be found
+// and garbage-collected.
+const ManagedByGlobalLabel = "authorization.openshift.io/managed-by-global"
+
+// Cluster is a single member of the multi-cluster federation that global
+// role bindings are propagated to.
+type Cluster struct {
+	Name string
+}
+
+// ClusterRegistry lists the clusters a GlobalRoleBinding should be
+// propagated to.
+type ClusterRegistry interface {
+	ListClusters() ([]Cluster, error)
+}
+
+// ClusterClientsetGetter returns the ClusterRoleBindings client to use for a
+// given member cluster.
+type ClusterClientsetGetter func(clusterName string) (rbacv1client.ClusterRoleBindingsGetter, error)
+
+// GlobalRoleBindingLister returns the current set of GlobalRoleBindings to
+// reconcile.
+type GlobalRoleBindingLister func() ([]authorizationapi.GlobalRoleBinding, error)
+
+// Controller reconciles GlobalRoleBindings against the ClusterRoleBindings
+// they own in every member cluster, correcting drift and, when a
+// GlobalRoleBinding is deleted, removing its ClusterRoleBinding from every
+// cluster still in the registry. ClusterRoleBindings left in a cluster that
+// has been removed from the registry are not deleted by the Controller.
+type Controller struct {
+	globalBindings  GlobalRoleBindingLister
+	clusterRegistry ClusterRegistry
+	clientsetFor    ClusterClientsetGetter
+
+	queue workqueue.RateLimitingInterface
+}
+
+// NewController builds a Controller. clientsetFor is expected to return a
+// client scoped to the service identity the controller uses to write into
+// member clusters; it must never be the identity of the user that created
+// the GlobalRoleBinding, since GlobalRoleBindings are only permitted to be
+// mutated by callers with cluster-admin-equivalent access in the REST layer.
+func NewController(globalBindings GlobalRoleBindingLister, clusterRegistry ClusterRegistry, clientsetFor ClusterClientsetGetter) *Controller {
+	return &Controller{
+		globalBindings:  globalBindings,
+		clusterRegistry: clusterRegistry,
+		clientsetFor:    clientsetFor,
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "global-role-binding"),
+	}
+}
+
+// Enqueue requeues the named GlobalRoleBinding for reconciliation, e.g. in
+// response to a watch event or a change in cluster federation membership.
+func (c *Controller) Enqueue(name string) {
+	c.queue.Add(name)
+}
+
+// EnqueueAll requeues every known GlobalRoleBinding. Callers should invoke
+// this whenever the ClusterRegistry reports a membership change, since a
+// newly joined cluster needs every existing binding propagated to it and a
+// removed cluster's orphaned ClusterRoleBindings can no longer be reached by
+// per-binding reconciliation.
+func (c *Controller) EnqueueAll() {
+	bindings, err := c.globalBindings()
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("unable to list global role bindings for federation membership change: %v", err))
+		return
+	}
+	for _, binding := range bindings {
+		c.Enqueue(binding.Name)
+	}
+}
+
+// Run starts workers processing the queue until stopCh is closed.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting global role binding controller")
+	defer klog.Infof("Shutting down global role binding controller")
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(key.(string)); err != nil {
+		runtime.HandleError(fmt.Errorf("error syncing global role binding %q: %v", key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) sync(name string) error {
+	bindings, err := c.globalBindings()
+	if err != nil {
+		return err
+	}
+	var binding *authorizationapi.GlobalRoleBinding
+	for i := range bindings {
+		if bindings[i].Name == name {
+			binding = &bindings[i]
+			break
+		}
+	}
+
+	clusters, err := c.clusterRegistry.ListClusters()
+	if err != nil {
+		return err
+	}
+
+	if binding == nil {
+		// The GlobalRoleBinding was deleted; clean up everything we
+		// propagated into every cluster we can still reach. A cluster that
+		// has itself left the federation is not reachable through
+		// clientsetFor and is expected to be cleaned up independently (e.g.
+		// by whatever process decommissions the cluster).
+		return c.deleteFromClusters(name, clusters)
+	}
+
+	desired := desiredClusterRoleBinding(binding)
+	var errs []error
+	for _, cluster := range clusters {
+		if err := c.reconcileCluster(cluster, desired); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %v", cluster.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("propagation of %q failed on %d cluster(s): %v", name, len(errs), errs)
+	}
+	return nil
+}
+
+func desiredClusterRoleBinding(binding *authorizationapi.GlobalRoleBinding) *rbacv1.ClusterRoleBinding {
+	subjects := make([]rbacv1.Subject, 0, len(binding.Subjects))
+	for _, s := range binding.Subjects {
+		subjects = append(subjects, rbacv1.Subject{Kind: s.Kind, Namespace: s.Namespace, Name: s.Name})
+	}
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   binding.Name,
+			Labels: map[string]string{ManagedByGlobalLabel: binding.Name},
+		},
+		Subjects: subjects,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     binding.RoleRef.Name,
+		},
+	}
+}
+
+// reconcileCluster ensures the desired ClusterRoleBinding exists verbatim in
+// the given member cluster, correcting any drift introduced out-of-band.
+func (c *Controller) reconcileCluster(cluster Cluster, desired *rbacv1.ClusterRoleBinding) error {
+	client, err := c.clientsetFor(cluster.Name)
+	if err != nil {
+		return err
+	}
+
+	existing, err := client.ClusterRoleBindings().Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err := client.ClusterRoleBindings().Create(desired)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if roleRefChanged(existing.RoleRef, desired.RoleRef) {
+		// RoleRef is immutable on a RBAC binding; the only way to correct
+		// drift here is to delete it (preconditioned on its UID) and
+		// recreate it. The two calls are not atomic: if Create fails, the
+		// binding is absent until the next sync.
+		if err := client.ClusterRoleBindings().Delete(desired.Name, metav1.NewPreconditionDeleteOptions(string(existing.UID))); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		_, err := client.ClusterRoleBindings().Create(desired)
+		return err
+	}
+
+	if reflect.DeepEqual(existing.Subjects, desired.Subjects) && labelsContain(existing.Labels, desired.Labels) {
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Subjects = desired.Subjects
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	for k, v := range desired.Labels {
+		updated.Labels[k] = v
+	}
+	_, err = client.ClusterRoleBindings().Update(updated)
+	return err
+}
+
+// deleteFromClusters removes the ClusterRoleBinding named name, previously
+// propagated by a now-deleted GlobalRoleBinding, from every given cluster.
+func (c *Controller) deleteFromClusters(name string, clusters []Cluster) error {
+	var errs []error
+	for _, cluster := range clusters {
+		client, err := c.clientsetFor(cluster.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := client.ClusterRoleBindings().Delete(name, nil); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("cleanup of %q failed: %v", name, errs)
+	}
+	return nil
+}
+
+func roleRefChanged(existing, desired rbacv1.RoleRef) bool {
+	return existing.APIGroup != desired.APIGroup || existing.Kind != desired.Kind || existing.Name != desired.Name
+}
+
+func labelsContain(have, want map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package globalrolebinding
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+)
+
+func newTestController(bindings []authorizationapi.GlobalRoleBinding, clusters []Cluster) (*Controller, map[string]*fake.Clientset) {
+	clientsets := map[string]*fake.Clientset{}
+	for _, c := range clusters {
+		clientsets[c.Name] = fake.NewSimpleClientset()
+	}
+
+	controller := NewController(
+		func() ([]authorizationapi.GlobalRoleBinding, error) { return bindings, nil },
+		staticClusterRegistry(clusters),
+		func(name string) (rbacv1client.ClusterRoleBindingsGetter, error) {
+			return clientsets[name].RbacV1(), nil
+		},
+	)
+	return controller, clientsets
+}
+
+type staticClusterRegistry []Cluster
+
+func (s staticClusterRegistry) ListClusters() ([]Cluster, error) {
+	return []Cluster(s), nil
+}
+
+func testBinding() authorizationapi.GlobalRoleBinding {
+	return authorizationapi.GlobalRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "global-admins"},
+		Subjects: []kapi.ObjectReference{
+			{Kind: "User", Name: "alice"},
+		},
+		RoleRef: kapi.ObjectReference{Name: "cluster-admin"},
+	}
+}
+
+func TestSyncPropagatesToEveryCluster(t *testing.T) {
+	binding := testBinding()
+	clusters := []Cluster{{Name: "east"}, {Name: "west"}}
+	controller, clientsets := newTestController([]authorizationapi.GlobalRoleBinding{binding}, clusters)
+
+	if err := controller.sync(binding.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, cluster := range clusters {
+		crb, err := clientsets[cluster.Name].RbacV1().ClusterRoleBindings().Get(binding.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected binding propagated to %s: %v", cluster.Name, err)
+		}
+		if crb.Labels[ManagedByGlobalLabel] != binding.Name {
+			t.Fatalf("expected managed-by-global label on %s, got %v", cluster.Name, crb.Labels)
+		}
+		if len(crb.Subjects) != 1 || crb.Subjects[0].Name != "alice" {
+			t.Fatalf("expected alice as a subject on %s, got %#v", cluster.Name, crb.Subjects)
+		}
+	}
+}
+
+func TestSyncCorrectsDrift(t *testing.T) {
+	binding := testBinding()
+	clusters := []Cluster{{Name: "east"}}
+	controller, clientsets := newTestController([]authorizationapi.GlobalRoleBinding{binding}, clusters)
+
+	if err := controller.sync(binding.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulate an out-of-band mutation removing the managed subject.
+	drifted, err := clientsets["east"].RbacV1().ClusterRoleBindings().Get(binding.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	drifted.Subjects = []rbacv1.Subject{{Kind: "User", Name: "mallory"}}
+	if _, err := clientsets["east"].RbacV1().ClusterRoleBindings().Update(drifted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := controller.sync(binding.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	corrected, err := clientsets["east"].RbacV1().ClusterRoleBindings().Get(binding.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(corrected.Subjects) != 1 || corrected.Subjects[0].Name != "alice" {
+		t.Fatalf("expected drift corrected back to alice, got %#v", corrected.Subjects)
+	}
+}
+
+func TestSyncCleansUpAfterBindingDeletion(t *testing.T) {
+	binding := testBinding()
+	clusters := []Cluster{{Name: "east"}, {Name: "west"}}
+	controller, clientsets := newTestController([]authorizationapi.GlobalRoleBinding{binding}, clusters)
+
+	if err := controller.sync(binding.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The GlobalRoleBinding itself is deleted; the lister now returns nothing.
+	controller.globalBindings = func() ([]authorizationapi.GlobalRoleBinding, error) { return nil, nil }
+	if err := controller.sync(binding.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, cluster := range clusters {
+		if _, err := clientsets[cluster.Name].RbacV1().ClusterRoleBindings().Get(binding.Name, metav1.GetOptions{}); err == nil {
+			t.Fatalf("expected binding to be cleaned up on %s", cluster.Name)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package globalrolebinding
+
+import (
+	"fmt"
+
+	authorizer "k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// clusterAdminEquivalentAttributes describes the access a caller must hold
+// on the federation control plane to mutate or remove a propagating
+// GlobalRoleBinding. A caller lacking this access could otherwise use a
+// GlobalRoleBinding to hand themselves cluster-admin on every member
+// cluster, so Update and Delete are refused unless it is satisfied.
+var clusterAdminEquivalentAttributes = authorizer.AttributesRecord{
+	Verb:            "*",
+	APIGroup:        "*",
+	Resource:        "*",
+	Name:            "*",
+	ResourceRequest: true,
+}
+
+// AuthorizeMutation refuses Update and Delete of a GlobalRoleBinding unless
+// user holds access equivalent to cluster-admin, preventing a binding from
+// being used as a lateral privilege-escalation path into every member
+// cluster.
+func AuthorizeMutation(a authorizer.Authorizer, user authorizer.Attributes) error {
+	attrs := clusterAdminEquivalentAttributes
+	attrs.User = user.GetUser()
+
+	decision, reason, err := a.Authorize(attrs)
+	if err != nil {
+		return fmt.Errorf("unable to determine cluster-admin-equivalent access for %q: %v", user.GetUser().GetName(), err)
+	}
+	if decision != authorizer.DecisionAllow {
+		return fmt.Errorf("user %q may not modify or delete GlobalRoleBindings without cluster-admin-equivalent access: %s", user.GetUser().GetName(), reason)
+	}
+	return nil
+}
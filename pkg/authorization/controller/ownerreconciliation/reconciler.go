@@ -0,0 +1,189 @@
+// Package ownerreconciliation continuously drives a marked set of
+// ClusterRoles and ClusterRoleBindings back to their authoritative, in-code
+// definition, correcting any out-of-band drift.
+package ownerreconciliation
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+)
+
+// OwnerAnnotation marks a ClusterRole or ClusterRoleBinding as owned by a
+// particular control plane and therefore authoritative input to this
+// reconciler. Objects without this annotation are left alone.
+const OwnerAnnotation = "authorization.openshift.io/owner"
+
+// ExpectedClusterRole is the authoritative definition of a managed
+// ClusterRole.
+type ExpectedClusterRole struct {
+	Owner string
+	Role  *rbacv1.ClusterRole
+}
+
+// ExpectedClusterRoleBinding is the authoritative definition of a managed
+// ClusterRoleBinding.
+type ExpectedClusterRoleBinding struct {
+	Owner   string
+	Binding *rbacv1.ClusterRoleBinding
+}
+
+// Reconciler periodically drives the configured ClusterRoles and
+// ClusterRoleBindings back to their expected state.
+type Reconciler struct {
+	client rbacv1client.RbacV1Interface
+
+	roles    []ExpectedClusterRole
+	bindings []ExpectedClusterRoleBinding
+
+	Interval time.Duration
+}
+
+// NewReconciler builds a Reconciler for the given authoritative set of
+// ClusterRoles and ClusterRoleBindings.
+func NewReconciler(client rbacv1client.RbacV1Interface, roles []ExpectedClusterRole, bindings []ExpectedClusterRoleBinding) *Reconciler {
+	return &Reconciler{
+		client:   client,
+		roles:    roles,
+		bindings: bindings,
+		Interval: 10 * time.Second,
+	}
+}
+
+// Run reconciles once immediately, then again every Interval until stopCh is
+// closed.
+func (r *Reconciler) Run(stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := r.ReconcileOnce(); err != nil {
+			// Errors are transient (a concurrent writer, a deleted
+			// namespace-scoped owner) and are retried on the next tick.
+			return
+		}
+	}, r.Interval, stopCh)
+}
+
+// ReconcileOnce drives every expected object back to its authoritative
+// definition and, after attempting all of them, returns a single error
+// listing every failure, if any.
+func (r *Reconciler) ReconcileOnce() error {
+	var errs []error
+	for _, expected := range r.roles {
+		if err := r.reconcileClusterRole(expected); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, expected := range r.bindings {
+		if err := r.reconcileClusterRoleBinding(expected); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("reconciliation failed for %d object(s): %v", len(errs), errs)
+	}
+	return nil
+}
+
+func (r *Reconciler) reconcileClusterRole(expected ExpectedClusterRole) error {
+	existing, err := r.client.ClusterRoles().Get(expected.Role.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		toCreate := expected.Role.DeepCopy()
+		toCreate.Annotations = annotationsWithOwner(toCreate.Annotations, expected.Owner)
+		_, err := r.client.ClusterRoles().Create(toCreate)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Annotations[OwnerAnnotation] != expected.Owner {
+		// Not ours to manage.
+		return nil
+	}
+
+	if reflect.DeepEqual(existing.Rules, expected.Role.Rules) {
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Rules = expected.Role.Rules
+	updated.ObjectMeta = EnsureObjectMetaIsUpdated(existing.ObjectMeta, updated.ObjectMeta)
+	_, err = r.client.ClusterRoles().Update(updated)
+	return err
+}
+
+func (r *Reconciler) reconcileClusterRoleBinding(expected ExpectedClusterRoleBinding) error {
+	existing, err := r.client.ClusterRoleBindings().Get(expected.Binding.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		toCreate := expected.Binding.DeepCopy()
+		toCreate.Annotations = annotationsWithOwner(toCreate.Annotations, expected.Owner)
+		_, err := r.client.ClusterRoleBindings().Create(toCreate)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Annotations[OwnerAnnotation] != expected.Owner {
+		return nil
+	}
+
+	if existing.RoleRef != expected.Binding.RoleRef {
+		// RoleRef is immutable on an RBAC binding; correct drift by
+		// deleting it (preconditioned on its UID) and recreating it. The
+		// two calls are not atomic: if Create fails, the binding is absent
+		// until the next pass.
+		if err := r.client.ClusterRoleBindings().Delete(existing.Name, metav1.NewPreconditionDeleteOptions(string(existing.UID))); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		toCreate := expected.Binding.DeepCopy()
+		toCreate.Annotations = annotationsWithOwner(toCreate.Annotations, expected.Owner)
+		_, err := r.client.ClusterRoleBindings().Create(toCreate)
+		return err
+	}
+
+	if reflect.DeepEqual(existing.Subjects, expected.Binding.Subjects) {
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Subjects = expected.Binding.Subjects
+	updated.ObjectMeta = EnsureObjectMetaIsUpdated(existing.ObjectMeta, updated.ObjectMeta)
+	_, err = r.client.ClusterRoleBindings().Update(updated)
+	return err
+}
+
+func annotationsWithOwner(annotations map[string]string, owner string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[OwnerAnnotation] = owner
+	return annotations
+}
+
+// EnsureObjectMetaIsUpdated returns an ObjectMeta suitable for an update of
+// existing to updated: it keeps updated's name/namespace/resourceVersion (so
+// the write can succeed) while preserving every label and annotation on
+// existing that this reconciler does not itself own, so that a user who
+// hand-annotated a managed object does not have that annotation clobbered on
+// the next reconcile pass.
+func EnsureObjectMetaIsUpdated(existing, updated metav1.ObjectMeta) metav1.ObjectMeta {
+	merged := existing.DeepCopy()
+	merged.ResourceVersion = updated.ResourceVersion
+	if merged.Labels == nil {
+		merged.Labels = map[string]string{}
+	}
+	for k, v := range updated.Labels {
+		merged.Labels[k] = v
+	}
+	if merged.Annotations == nil {
+		merged.Annotations = map[string]string{}
+	}
+	for k, v := range updated.Annotations {
+		merged.Annotations[k] = v
+	}
+	return *merged
+}
@@ -0,0 +1,118 @@
+package ownerreconciliation
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testOwner = "test-controlplane"
+
+func TestReconcileOnceCreatesMissingObjects(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	expectedRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "managed-role"},
+		Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"pods"}}},
+	}
+
+	r := NewReconciler(client.RbacV1(), []ExpectedClusterRole{{Owner: testOwner, Role: expectedRole}}, nil)
+	if err := r.ReconcileOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	created, err := client.RbacV1().ClusterRoles().Get("managed-role", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected role to be created: %v", err)
+	}
+	if created.Annotations[OwnerAnnotation] != testOwner {
+		t.Fatalf("expected owner annotation %q, got %v", testOwner, created.Annotations)
+	}
+}
+
+func TestReconcileOnceCorrectsRuleDrift(t *testing.T) {
+	expectedRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "managed-role"},
+		Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get", "list"}, Resources: []string{"pods"}}},
+	}
+	existing := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "managed-role",
+			Annotations: map[string]string{OwnerAnnotation: testOwner, "user-added": "keep-me"},
+		},
+		Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"pods"}}},
+	}
+	client := fake.NewSimpleClientset(existing)
+
+	r := NewReconciler(client.RbacV1(), []ExpectedClusterRole{{Owner: testOwner, Role: expectedRole}}, nil)
+	if err := r.ReconcileOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	corrected, err := client.RbacV1().ClusterRoles().Get("managed-role", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(corrected.Rules) != 1 || len(corrected.Rules[0].Verbs) != 2 {
+		t.Fatalf("expected drifted rules to be corrected, got %#v", corrected.Rules)
+	}
+	if corrected.Annotations["user-added"] != "keep-me" {
+		t.Fatalf("expected unrelated annotation to survive reconciliation, got %v", corrected.Annotations)
+	}
+}
+
+func TestReconcileOnceIgnoresUnownedObjects(t *testing.T) {
+	expectedRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "unmanaged-role"},
+		Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get"}, Resources: []string{"pods"}}},
+	}
+	existing := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "unmanaged-role"},
+		Rules:      []rbacv1.PolicyRule{{Verbs: []string{"delete"}, Resources: []string{"pods"}}},
+	}
+	client := fake.NewSimpleClientset(existing)
+
+	r := NewReconciler(client.RbacV1(), []ExpectedClusterRole{{Owner: testOwner, Role: expectedRole}}, nil)
+	if err := r.ReconcileOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	untouched, err := client.RbacV1().ClusterRoles().Get("unmanaged-role", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(untouched.Rules) != 1 || untouched.Rules[0].Verbs[0] != "delete" {
+		t.Fatalf("expected unowned role to be left alone, got %#v", untouched.Rules)
+	}
+}
+
+func TestReconcileOnceRecreatesOnRoleRefChange(t *testing.T) {
+	expectedBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "managed-binding"},
+		Subjects:   []rbacv1.Subject{{Kind: "User", Name: "alice"}},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+	}
+	existing := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "managed-binding",
+			Annotations: map[string]string{OwnerAnnotation: testOwner},
+		},
+		Subjects: []rbacv1.Subject{{Kind: "User", Name: "alice"}},
+		RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+	}
+	client := fake.NewSimpleClientset(existing)
+
+	r := NewReconciler(client.RbacV1(), nil, []ExpectedClusterRoleBinding{{Owner: testOwner, Binding: expectedBinding}})
+	if err := r.ReconcileOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	corrected, err := client.RbacV1().ClusterRoleBindings().Get("managed-binding", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if corrected.RoleRef.Name != "edit" {
+		t.Fatalf("expected roleRef drift corrected via delete+recreate, got %#v", corrected.RoleRef)
+	}
+}
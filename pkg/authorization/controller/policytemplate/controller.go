@@ -0,0 +1,314 @@
+// Package policytemplate implements a controller that materializes
+// PolicyTemplates into newly created namespaces, replacing a hardcoded set
+// of bootstrap service-account Roles and RoleBindings with a configurable,
+// versioned template that supports ${PROJECT}, ${REQUESTER}, and
+// ${SA:<name>} variable substitution.
+package policytemplate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// PolicyTemplateLister returns the current set of PolicyTemplates to
+// materialize into every namespace.
+type PolicyTemplateLister func() ([]authorizationapi.PolicyTemplate, error)
+
+// NamespaceGetter resolves the Namespace backing a Project, so that created
+// objects can be owned by it and its requester annotation read.
+type NamespaceGetter func(name string) (*corev1.Namespace, error)
+
+// NamespaceAnnotationSetter persists the PolicyTemplateVersionAnnotation on a
+// namespace once materialization completes, so a subsequent sync can be
+// skipped if nothing has changed.
+type NamespaceAnnotationSetter func(namespace string, annotations map[string]string) error
+
+// requesterAnnotation carries the user a Project was requested by, the same
+// annotation the project-request REST layer stamps onto the Namespace.
+const requesterAnnotation = "openshift.io/requester"
+
+// Controller materializes every known PolicyTemplate's Roles and
+// RoleBindings into each namespace, re-materializing whenever a template's
+// ResourceVersion moves past what the namespace's version annotation
+// records.
+type Controller struct {
+	templates  PolicyTemplateLister
+	namespaces NamespaceGetter
+	setVersion NamespaceAnnotationSetter
+	rbac       rbacv1client.RbacV1Interface
+
+	queue workqueue.RateLimitingInterface
+}
+
+// NewController builds a Controller.
+func NewController(templates PolicyTemplateLister, namespaces NamespaceGetter, setVersion NamespaceAnnotationSetter, rbac rbacv1client.RbacV1Interface) *Controller {
+	return &Controller{
+		templates:  templates,
+		namespaces: namespaces,
+		setVersion: setVersion,
+		rbac:       rbac,
+		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "policy-template"),
+	}
+}
+
+// Enqueue requeues namespace for (re-)materialization, e.g. in response to a
+// Project/Namespace add or a PolicyTemplate update.
+func (c *Controller) Enqueue(namespace string) {
+	c.queue.Add(namespace)
+}
+
+// Run starts workers processing the queue until stopCh is closed.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting policy template controller")
+	defer klog.Infof("Shutting down policy template controller")
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	<-stopCh
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.sync(key.(string)); err != nil {
+		runtime.HandleError(fmt.Errorf("error materializing policy templates into namespace %q: %v", key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// MaterializeNamespace runs sync for namespace synchronously, bypassing the
+// workqueue. It is exported for callers (tests, and Run-less integrations)
+// that need to force an immediate materialization rather than waiting on a
+// worker to drain the queue.
+func (c *Controller) MaterializeNamespace(namespace string) error {
+	return c.sync(namespace)
+}
+
+func (c *Controller) sync(namespace string) error {
+	ns, err := c.namespaces(namespace)
+	if err != nil {
+		return err
+	}
+
+	templates, err := c.templates()
+	if err != nil {
+		return err
+	}
+
+	applied := parseVersions(ns.Annotations[authorizationapi.PolicyTemplateVersionAnnotation])
+	changed := false
+
+	for _, template := range templates {
+		if applied[template.Name] == template.ResourceVersion {
+			continue
+		}
+		if err := c.materialize(ns, template); err != nil {
+			return fmt.Errorf("materializing policy template %q into namespace %q: %v", template.Name, namespace, err)
+		}
+		applied[template.Name] = template.ResourceVersion
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return c.setVersion(namespace, map[string]string{authorizationapi.PolicyTemplateVersionAnnotation: formatVersions(applied)})
+}
+
+// materialize creates or updates every Role and RoleBinding template defines
+// in ns, owned by ns so that namespace deletion garbage-collects them.
+func (c *Controller) materialize(ns *corev1.Namespace, template authorizationapi.PolicyTemplate) error {
+	vars := substitutionVars{project: ns.Name, requester: ns.Annotations[requesterAnnotation]}
+	owner := ownerReference(ns)
+
+	for _, rt := range template.RoleTemplates {
+		role := materializeRole(rt, ns.Name, vars, owner)
+		if err := applyRole(c.rbac.Roles(ns.Name), role); err != nil {
+			return err
+		}
+	}
+	for _, rbt := range template.RoleBindingTemplates {
+		binding := materializeRoleBinding(rbt, ns.Name, vars, owner)
+		if err := applyRoleBinding(c.rbac.RoleBindings(ns.Name), binding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DryRun materializes template as if it were applied to namespace, without
+// writing anything, returning exactly the objects sync would create. It is a
+// plain function; no API endpoint in this package serves it.
+func DryRun(ns *corev1.Namespace, template authorizationapi.PolicyTemplate) ([]rbacv1.Role, []rbacv1.RoleBinding) {
+	vars := substitutionVars{project: ns.Name, requester: ns.Annotations[requesterAnnotation]}
+	owner := ownerReference(ns)
+
+	roles := make([]rbacv1.Role, 0, len(template.RoleTemplates))
+	for _, rt := range template.RoleTemplates {
+		roles = append(roles, materializeRole(rt, ns.Name, vars, owner))
+	}
+	bindings := make([]rbacv1.RoleBinding, 0, len(template.RoleBindingTemplates))
+	for _, rbt := range template.RoleBindingTemplates {
+		bindings = append(bindings, materializeRoleBinding(rbt, ns.Name, vars, owner))
+	}
+	return roles, bindings
+}
+
+func ownerReference(ns *corev1.Namespace) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Name:       ns.Name,
+		UID:        ns.UID,
+		Controller: &controller,
+	}
+}
+
+func materializeRole(rt authorizationapi.RoleTemplate, namespace string, vars substitutionVars, owner metav1.OwnerReference) rbacv1.Role {
+	return rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            vars.substitute(rt.Name),
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Rules: rt.Rules,
+	}
+}
+
+func materializeRoleBinding(rbt authorizationapi.RoleBindingTemplate, namespace string, vars substitutionVars, owner metav1.OwnerReference) rbacv1.RoleBinding {
+	subjects := make([]rbacv1.Subject, 0, len(rbt.Subjects))
+	for _, s := range rbt.Subjects {
+		subjects = append(subjects, materializeSubject(s, namespace, vars))
+	}
+	return rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            vars.substitute(rbt.Name),
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Subjects: subjects,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbt.RoleRef.Kind,
+			Name:     vars.substitute(rbt.RoleRef.Name),
+		},
+	}
+}
+
+func materializeSubject(s kapi.ObjectReference, namespace string, vars substitutionVars) rbacv1.Subject {
+	if isServiceAccountMarker(s.Name) {
+		return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: serviceAccountName(s.Name)}
+	}
+	return rbacv1.Subject{Kind: s.Kind, Namespace: s.Namespace, Name: vars.substitute(s.Name)}
+}
+
+func applyRole(client rbacv1client.RoleInterface, desired rbacv1.Role) error {
+	existing, err := client.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err := client.Create(&desired)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	updated := existing.DeepCopy()
+	updated.Rules = desired.Rules
+	updated.OwnerReferences = desired.OwnerReferences
+	_, err = client.Update(updated)
+	return err
+}
+
+func applyRoleBinding(client rbacv1client.RoleBindingInterface, desired rbacv1.RoleBinding) error {
+	existing, err := client.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err := client.Create(&desired)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if roleRefChanged(existing.RoleRef, desired.RoleRef) {
+		// RoleRef is immutable; correct drift by deleting and recreating.
+		if err := client.Delete(desired.Name, metav1.NewPreconditionDeleteOptions(string(existing.UID))); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		_, err := client.Create(&desired)
+		return err
+	}
+	updated := existing.DeepCopy()
+	updated.Subjects = desired.Subjects
+	updated.OwnerReferences = desired.OwnerReferences
+	_, err = client.Update(updated)
+	return err
+}
+
+func roleRefChanged(existing, desired rbacv1.RoleRef) bool {
+	return existing.APIGroup != desired.APIGroup || existing.Kind != desired.Kind || existing.Name != desired.Name
+}
+
+// parseVersions decodes the PolicyTemplateVersionAnnotation, a comma
+// separated list of "templateName=resourceVersion" pairs, into a map keyed
+// by template name.
+func parseVersions(annotation string) map[string]string {
+	out := map[string]string{}
+	if len(annotation) == 0 {
+		return out
+	}
+	for _, pair := range strings.Split(annotation, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		out[parts[0]] = parts[1]
+	}
+	return out
+}
+
+// formatVersions encodes applied back into the annotation format
+// parseVersions reads, with entries sorted by template name for a stable
+// representation.
+func formatVersions(applied map[string]string) string {
+	names := make([]string, 0, len(applied))
+	for name := range applied {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+applied[name])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,175 @@
+package policytemplate
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func newTestController(namespace *corev1.Namespace, templates []authorizationapi.PolicyTemplate) (*Controller, *fake.Clientset) {
+	clientset := fake.NewSimpleClientset(namespace)
+
+	controller := NewController(
+		func() ([]authorizationapi.PolicyTemplate, error) { return templates, nil },
+		func(name string) (*corev1.Namespace, error) {
+			return clientset.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		},
+		func(name string, annotations map[string]string) error {
+			ns, err := clientset.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			updated := ns.DeepCopy()
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			for k, v := range annotations {
+				updated.Annotations[k] = v
+			}
+			_, err = clientset.CoreV1().Namespaces().Update(updated)
+			return err
+		},
+		clientset.RbacV1(),
+	)
+	return controller, clientset
+}
+
+func testTemplate() authorizationapi.PolicyTemplate {
+	return authorizationapi.PolicyTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", ResourceVersion: "1"},
+		RoleTemplates: []authorizationapi.RoleTemplate{
+			{Name: "${PROJECT}-deployer", Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+		},
+		RoleBindingTemplates: []authorizationapi.RoleBindingTemplate{
+			{
+				Name: "${PROJECT}-deployers-binding",
+				Subjects: []kapi.ObjectReference{
+					{Kind: "User", Name: "${REQUESTER}"},
+					{Kind: "ServiceAccount", Name: "${SA:deployer}"},
+				},
+				RoleRef: kapi.ObjectReference{Kind: "Role", Name: "${PROJECT}-deployer"},
+			},
+		},
+	}
+}
+
+func testNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "myproject",
+			UID:         types.UID("ns-uid"),
+			Annotations: map[string]string{requesterAnnotation: "alice"},
+		},
+	}
+}
+
+func TestSyncMaterializesSubstitutedRoleAndBinding(t *testing.T) {
+	ns := testNamespace()
+	template := testTemplate()
+	controller, clientset := newTestController(ns, []authorizationapi.PolicyTemplate{template})
+
+	if err := controller.sync(ns.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	role, err := clientset.RbacV1().Roles(ns.Name).Get("myproject-deployer", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected role to be materialized: %v", err)
+	}
+	if role.OwnerReferences[0].UID != ns.UID {
+		t.Fatalf("expected role owned by namespace, got %#v", role.OwnerReferences)
+	}
+
+	binding, err := clientset.RbacV1().RoleBindings(ns.Name).Get("myproject-deployers-binding", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected rolebinding to be materialized: %v", err)
+	}
+	if binding.RoleRef.Name != "myproject-deployer" {
+		t.Fatalf("expected RoleRef substituted, got %q", binding.RoleRef.Name)
+	}
+
+	foundRequester, foundSA := false, false
+	for _, s := range binding.Subjects {
+		if s.Kind == "User" && s.Name == "alice" {
+			foundRequester = true
+		}
+		if s.Kind == rbacv1.ServiceAccountKind && s.Name == "deployer" && s.Namespace == ns.Name {
+			foundSA = true
+		}
+	}
+	if !foundRequester {
+		t.Errorf("expected ${REQUESTER} substituted to alice, got %#v", binding.Subjects)
+	}
+	if !foundSA {
+		t.Errorf("expected ${SA:deployer} substituted to a ServiceAccount subject, got %#v", binding.Subjects)
+	}
+
+	updatedNs, err := clientset.CoreV1().Namespaces().Get(ns.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := updatedNs.Annotations[authorizationapi.PolicyTemplateVersionAnnotation]; got != "bootstrap=1" {
+		t.Errorf("expected version annotation %q, got %q", "bootstrap=1", got)
+	}
+}
+
+func TestSyncSkipsAlreadyCurrentNamespace(t *testing.T) {
+	ns := testNamespace()
+	ns.Annotations[authorizationapi.PolicyTemplateVersionAnnotation] = "bootstrap=1"
+	template := testTemplate()
+	controller, clientset := newTestController(ns, []authorizationapi.PolicyTemplate{template})
+
+	if err := controller.sync(ns.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := clientset.RbacV1().Roles(ns.Name).Get("myproject-deployer", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected no materialization for an already-current namespace")
+	}
+}
+
+func TestSyncRematerializesOnTemplateUpdate(t *testing.T) {
+	ns := testNamespace()
+	ns.Annotations[authorizationapi.PolicyTemplateVersionAnnotation] = "bootstrap=1"
+	template := testTemplate()
+	template.ResourceVersion = "2"
+	template.RoleTemplates[0].Rules[0].Verbs = []string{"get", "list"}
+	controller, clientset := newTestController(ns, []authorizationapi.PolicyTemplate{template})
+
+	if err := controller.sync(ns.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	role, err := clientset.RbacV1().Roles(ns.Name).Get("myproject-deployer", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected role re-materialized: %v", err)
+	}
+	if len(role.Rules[0].Verbs) != 2 {
+		t.Fatalf("expected updated rules to be applied, got %#v", role.Rules[0].Verbs)
+	}
+}
+
+func TestDryRunDoesNotWriteAnything(t *testing.T) {
+	ns := testNamespace()
+	template := testTemplate()
+	_, clientset := newTestController(ns, []authorizationapi.PolicyTemplate{template})
+
+	roles, bindings := DryRun(ns, template)
+	if len(roles) != 1 || roles[0].Name != "myproject-deployer" {
+		t.Fatalf("expected the substituted role in the dry-run result, got %#v", roles)
+	}
+	if len(bindings) != 1 || bindings[0].RoleRef.Name != "myproject-deployer" {
+		t.Fatalf("expected the substituted rolebinding in the dry-run result, got %#v", bindings)
+	}
+
+	if _, err := clientset.RbacV1().Roles(ns.Name).Get("myproject-deployer", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected dry-run to not create anything")
+	}
+}
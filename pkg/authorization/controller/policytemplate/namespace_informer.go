@@ -0,0 +1,46 @@
+package policytemplate
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewNamespaceInformer builds the cache.SharedIndexInformer a server should
+// start (via Run, alongside Controller.Run) so that Controller fires on
+// every Project/Namespace creation automatically, rather than relying on a
+// caller to invoke MaterializeNamespace by hand. Update events are watched
+// too, since the requester annotation ${REQUESTER} substitutes from can be
+// set by the project-request REST layer slightly after the Namespace is
+// created.
+func NewNamespaceInformer(client corev1client.NamespacesGetter, controller *Controller) cache.SharedIndexInformer {
+	informer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.Namespaces().List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.Namespaces().Watch(options)
+			},
+		},
+		&corev1.Namespace{},
+		0,
+		cache.Indexers{},
+	)
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { enqueueNamespace(controller, obj) },
+		UpdateFunc: func(_, obj interface{}) { enqueueNamespace(controller, obj) },
+	})
+	return informer
+}
+
+func enqueueNamespace(controller *Controller, obj interface{}) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	controller.Enqueue(ns.Name)
+}
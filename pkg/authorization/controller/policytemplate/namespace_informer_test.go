@@ -0,0 +1,71 @@
+package policytemplate
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+)
+
+// TestNamespaceInformerEnqueuesOnCreate verifies that NewNamespaceInformer's
+// Add handler actually drives Controller end to end against a real
+// informer and Run loop: a Namespace created after the informer starts is
+// materialized automatically, without anything calling
+// Controller.MaterializeNamespace by hand the way a Project/namespace
+// creation would in a real server.
+func TestNamespaceInformerEnqueuesOnCreate(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	template := testTemplate()
+
+	controller := NewController(
+		func() ([]authorizationapi.PolicyTemplate, error) { return []authorizationapi.PolicyTemplate{template}, nil },
+		func(name string) (*corev1.Namespace, error) {
+			return clientset.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		},
+		func(name string, annotations map[string]string) error {
+			ns, err := clientset.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			updated := ns.DeepCopy()
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			for k, v := range annotations {
+				updated.Annotations[k] = v
+			}
+			_, err = clientset.CoreV1().Namespaces().Update(updated)
+			return err
+		},
+		clientset.RbacV1(),
+	)
+
+	informer := NewNamespaceInformer(clientset.CoreV1(), controller)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go informer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		t.Fatal("timed out waiting for namespace informer cache to sync")
+	}
+	go controller.Run(1, stopCh)
+
+	ns := testNamespace()
+	if _, err := clientset.CoreV1().Namespaces().Create(ns); err != nil {
+		t.Fatalf("unexpected error creating namespace: %v", err)
+	}
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		_, getErr := clientset.RbacV1().RoleBindings(ns.Name).Get("myproject-deployers-binding", metav1.GetOptions{})
+		return getErr == nil, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the namespace informer's Add event to drive materialization without MaterializeNamespace being called directly: %v", err)
+	}
+}
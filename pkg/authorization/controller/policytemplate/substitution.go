@@ -0,0 +1,33 @@
+package policytemplate
+
+import "strings"
+
+// substitutionVars holds the values a PolicyTemplate's ${PROJECT} and
+// ${REQUESTER} markers expand to for a single materialization. ${SA:<name>}
+// is handled separately by materializeSubject, through isServiceAccountMarker
+// and serviceAccountName, since it carries its own argument rather than
+// expanding to a fixed value.
+type substitutionVars struct {
+	project   string
+	requester string
+}
+
+// substitute expands ${PROJECT} and ${REQUESTER} markers in s.
+// ${SA:<name>} is left untouched here; callers materializing a subject should
+// check isServiceAccountMarker/serviceAccountName first.
+func (v substitutionVars) substitute(s string) string {
+	s = strings.ReplaceAll(s, "${PROJECT}", v.project)
+	s = strings.ReplaceAll(s, "${REQUESTER}", v.requester)
+	return s
+}
+
+// isServiceAccountMarker reports whether s is of the form ${SA:<name>}.
+func isServiceAccountMarker(s string) bool {
+	return strings.HasPrefix(s, "${SA:") && strings.HasSuffix(s, "}")
+}
+
+// serviceAccountName extracts <name> from a ${SA:<name>} marker. Callers
+// must first confirm isServiceAccountMarker(s).
+func serviceAccountName(s string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(s, "${SA:"), "}")
+}
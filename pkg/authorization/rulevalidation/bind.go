@@ -0,0 +1,66 @@
+package rulevalidation
+
+import (
+	"context"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizer "k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// EscalationCheck performs the same check as ConfirmNoEscalation, but first
+// gives the acting user two ways around full rule coverage: policy may
+// exempt them outright (a configured super-user, or membership in a
+// configured privileged group such as system:masters), or they may hold the
+// "bind" verb, scoped by resourceName to roleRef, on the Role/ClusterRole
+// roleRef names. In either case the binding is authorized outright and
+// requestedRules is never inspected. The bind path lets a limited user be
+// delegated the ability to bind a specific powerful ClusterRole (even
+// cluster-admin) without being granted every rule it contains, the same
+// trade-off upstream RBAC makes for its own bind/escalate verbs. a may be
+// nil, in which case bind access is never considered. sources is forwarded
+// to ConfirmNoEscalation to annotate the resolved rule sources (e.g.
+// aggregation members) on denial; it may be nil.
+func EscalationCheck(ctx context.Context, a authorizer.Authorizer, policy EscalationPolicy, resolver RuleResolver, actingUser user.Info, namespace string, qualifiedResource schema.GroupResource, name string, roleRef rbacv1.RoleRef, sources []string, requestedRules []rbacv1.PolicyRule) error {
+	if policy.IsPrivileged(actingUser) {
+		return nil
+	}
+	if a != nil && bindAuthorized(a, actingUser, namespace, roleRef) {
+		return nil
+	}
+	var ref *rbacv1.RoleRef
+	if roleRef.Name != "" {
+		ref = &roleRef
+	}
+	return ConfirmNoEscalation(ctx, resolver, actingUser, namespace, qualifiedResource, name, ref, sources, requestedRules)
+}
+
+// bindAuthorized reports whether actingUser has been granted the "bind"
+// verb, scoped to roleRef by resourceName, on the rbac.authorization.k8s.io
+// role resource roleRef.Kind maps to. A RoleRef of Kind "Role" is checked
+// namespaced to namespace; a RoleRef of Kind "ClusterRole" is checked
+// cluster-scoped, matching how ClusterRoles themselves are not namespaced.
+func bindAuthorized(a authorizer.Authorizer, actingUser user.Info, namespace string, roleRef rbacv1.RoleRef) bool {
+	attrs := authorizer.AttributesRecord{
+		User:            actingUser,
+		Verb:            "bind",
+		APIGroup:        rbacv1.GroupName,
+		Resource:        bindResourceFor(roleRef.Kind),
+		Name:            roleRef.Name,
+		ResourceRequest: true,
+	}
+	if roleRef.Kind == "Role" {
+		attrs.Namespace = namespace
+	}
+
+	decision, _, err := a.Authorize(attrs)
+	return err == nil && decision == authorizer.DecisionAllow
+}
+
+func bindResourceFor(roleRefKind string) string {
+	if roleRefKind == "Role" {
+		return "roles"
+	}
+	return "clusterroles"
+}
@@ -0,0 +1,55 @@
+package rulevalidation
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizer "k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// staticAuthorizer grants access only for the exact verb/resource/name it
+// was constructed with, to exercise the "bind" short-circuit precisely.
+type staticAuthorizer struct {
+	allow authorizer.AttributesRecord
+}
+
+func (s staticAuthorizer) Authorize(a authorizer.Attributes) (authorizer.Decision, string, error) {
+	if a.GetVerb() == s.allow.Verb && a.GetAPIGroup() == s.allow.APIGroup && a.GetResource() == s.allow.Resource && a.GetName() == s.allow.Name {
+		return authorizer.DecisionAllow, "", nil
+	}
+	return authorizer.DecisionNoOpinion, "no matching rule", nil
+}
+
+var escalatingRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"}
+
+func TestEscalationCheckAllowsExplicitBindPermission(t *testing.T) {
+	a := staticAuthorizer{allow: authorizer.AttributesRecord{Verb: "bind", APIGroup: rbacv1.GroupName, Resource: "clusterroles", Name: "cluster-admin"}}
+	resolver := staticRuleResolver(nil) // holds nothing; bind access alone must suffice
+
+	err := EscalationCheck(context.Background(), a, EscalationPolicy{}, resolver, &user.DefaultInfo{Name: "limited-user"}, "", schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}, "my-binding", escalatingRef, nil, nil)
+	if err != nil {
+		t.Fatalf("expected bind permission to authorize the binding, got %v", err)
+	}
+}
+
+func TestEscalationCheckFallsBackToRuleCoverageWithoutBind(t *testing.T) {
+	a := staticAuthorizer{allow: authorizer.AttributesRecord{Verb: "bind", APIGroup: rbacv1.GroupName, Resource: "clusterroles", Name: "some-other-role"}}
+	resolver := staticRuleResolver(nil)
+
+	err := EscalationCheck(context.Background(), a, EscalationPolicy{}, resolver, &user.DefaultInfo{Name: "limited-user"}, "", schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}, "my-binding", escalatingRef, nil, []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}})
+	if err == nil {
+		t.Fatal("expected escalation error when bind access does not cover the referenced role")
+	}
+}
+
+func TestEscalationCheckNilAuthorizerFallsBackToRuleCoverage(t *testing.T) {
+	resolver := staticRuleResolver{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}
+
+	err := EscalationCheck(context.Background(), nil, EscalationPolicy{}, resolver, &user.DefaultInfo{Name: "admin"}, "", schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}, "my-binding", escalatingRef, nil, []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}})
+	if err != nil {
+		t.Fatalf("expected full rule coverage to authorize the binding, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package rulevalidation computes the exact PolicyRule delta between what a
+// user is requesting to grant and what they already hold, so that
+// escalation-forbidden errors can carry a machine-readable explanation
+// instead of only a human-readable message.
+package rulevalidation
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// atomicRule is a single (verb, apiGroup, resource, resourceName) or
+// (verb, nonResourceURL) component that a PolicyRule can be broken into.
+// Breaking rules down to this granularity lets coverage be computed
+// correctly even when wildcards only partially overlap.
+type atomicRule struct {
+	verb           string
+	apiGroup       string
+	resource       string
+	resourceName   string
+	nonResourceURL string
+}
+
+func (a atomicRule) isNonResource() bool {
+	return len(a.nonResourceURL) > 0
+}
+
+// breakdownRule expands a PolicyRule into its atomic components. A rule with
+// no ResourceNames is represented with a single empty-string resourceName,
+// meaning "any name".
+func breakdownRule(rule rbacv1.PolicyRule) []atomicRule {
+	var atoms []atomicRule
+
+	for _, url := range rule.NonResourceURLs {
+		for _, verb := range rule.Verbs {
+			atoms = append(atoms, atomicRule{verb: verb, nonResourceURL: url})
+		}
+	}
+
+	names := rule.ResourceNames
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	for _, verb := range rule.Verbs {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, name := range names {
+					atoms = append(atoms, atomicRule{verb: verb, apiGroup: group, resource: resource, resourceName: name})
+				}
+			}
+		}
+	}
+
+	return atoms
+}
+
+// covers reports whether the atomic unit is granted by owned, applying the
+// usual wildcard and subresource semantics: "*" matches anything in its
+// field, an owned rule with no resource names matches any requested name,
+// and a resource of "foo" does NOT cover a subresource request of
+// "foo/bar" unless the owned rule explicitly grants "foo/bar", "foo/*" or
+// "*".
+func covers(owned []rbacv1.PolicyRule, unit atomicRule) bool {
+	for _, rule := range owned {
+		if unit.isNonResource() {
+			if !matchesAny(rule.NonResourceURLs, unit.nonResourceURL) {
+				continue
+			}
+		} else {
+			if !matchesAny(rule.APIGroups, unit.apiGroup) {
+				continue
+			}
+			if !coversResource(rule.Resources, unit.resource) {
+				continue
+			}
+			if len(rule.ResourceNames) > 0 && !matchesAny(rule.ResourceNames, unit.resourceName) {
+				continue
+			}
+		}
+		if matchesAny(rule.Verbs, unit.verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(have []string, want string) bool {
+	for _, h := range have {
+		if h == "*" || h == want {
+			return true
+		}
+	}
+	return false
+}
+
+// coversResource applies subresource-aware matching: "*" covers everything,
+// an exact match covers itself, and "foo/*" covers any subresource of foo,
+// but "foo" does not cover "foo/bar".
+func coversResource(have []string, want string) bool {
+	for _, h := range have {
+		if h == "*" || h == want {
+			return true
+		}
+		if base, isWildcardSub := splitSubresourceWildcard(h); isWildcardSub {
+			if wantBase, _, hasSub := splitSubresource(want); hasSub && wantBase == base {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func splitSubresource(resource string) (base, sub string, ok bool) {
+	for i := 0; i < len(resource); i++ {
+		if resource[i] == '/' {
+			return resource[:i], resource[i+1:], true
+		}
+	}
+	return resource, "", false
+}
+
+func splitSubresourceWildcard(resource string) (base string, ok bool) {
+	base, sub, hasSub := splitSubresource(resource)
+	if hasSub && sub == "*" {
+		return base, true
+	}
+	return "", false
+}
+
+// Missing computes the PolicyRules requested that are not covered by owned,
+// returning the minimal set of rules a caller would need in addition to
+// owned in order for requested to be fully covered. Rules are coalesced by
+// grouping identical verb sets across resources within the same API group,
+// mirroring how the requested rules were likely authored.
+func Missing(owned, requested []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	var missingAtoms []atomicRule
+	for _, rule := range requested {
+		for _, atom := range breakdownRule(rule) {
+			if !covers(owned, atom) {
+				missingAtoms = append(missingAtoms, atom)
+			}
+		}
+	}
+	return coalesce(missingAtoms)
+}
+
+// coalesce groups atomic units back into PolicyRules: resource units are
+// grouped by (apiGroup, resourceName, verb-set) to recover a reasonably
+// minimal set of resources sharing identical access, and non-resource units
+// are grouped by verb-set across URLs.
+func coalesce(atoms []atomicRule) []rbacv1.PolicyRule {
+	type resourceGroupKey struct {
+		apiGroup     string
+		resourceName string
+		verbKey      string
+	}
+	resourceVerbs := map[string]map[string]bool{}   // groupKey -> verb set
+	resourceGroups := map[string]resourceGroupKey{}  // groupKey -> key fields
+	resourceResources := map[string]map[string]bool{} // groupKey -> resources
+
+	nonResourceVerbs := map[string]map[string]bool{} // url -> verb set
+
+	for _, atom := range atoms {
+		if atom.isNonResource() {
+			if nonResourceVerbs[atom.nonResourceURL] == nil {
+				nonResourceVerbs[atom.nonResourceURL] = map[string]bool{}
+			}
+			nonResourceVerbs[atom.nonResourceURL][atom.verb] = true
+			continue
+		}
+
+		// Group first by (apiGroup, resourceName, resource) to find the verb
+		// set for that resource, then merge resources whose verb sets match.
+		resKey := atom.apiGroup + "\x00" + atom.resourceName + "\x00" + atom.resource
+		if resourceVerbs[resKey] == nil {
+			resourceVerbs[resKey] = map[string]bool{}
+		}
+		resourceVerbs[resKey][atom.verb] = true
+		resourceGroups[resKey] = resourceGroupKey{apiGroup: atom.apiGroup, resourceName: atom.resourceName}
+		if resourceResources[resKey] == nil {
+			resourceResources[resKey] = map[string]bool{}
+		}
+		resourceResources[resKey][atom.resource] = true
+	}
+
+	// Merge resource entries sharing an identical (apiGroup, resourceName,
+	// verb-set) into one rule covering multiple resources.
+	type mergeKey struct {
+		apiGroup     string
+		resourceName string
+		verbKey      string
+	}
+	merged := map[mergeKey]map[string]bool{}
+	for resKey, verbs := range resourceVerbs {
+		group := resourceGroups[resKey]
+		key := mergeKey{apiGroup: group.apiGroup, resourceName: group.resourceName, verbKey: sortedJoin(verbs)}
+		if merged[key] == nil {
+			merged[key] = map[string]bool{}
+		}
+		for r := range resourceResources[resKey] {
+			merged[key][r] = true
+		}
+	}
+
+	var rules []rbacv1.PolicyRule
+	for key, resources := range merged {
+		rule := rbacv1.PolicyRule{
+			APIGroups: []string{key.apiGroup},
+			Resources: sortedSlice(resources),
+			Verbs:     sortedSlice(splitJoined(key.verbKey)),
+		}
+		if len(key.resourceName) > 0 {
+			rule.ResourceNames = []string{key.resourceName}
+		}
+		rules = append(rules, rule)
+	}
+
+	// Merge URLs sharing an identical verb set into one rule.
+	nonResourceMerged := map[string]map[string]bool{} // verb key -> urls
+	for url, verbs := range nonResourceVerbs {
+		key := sortedJoin(verbs)
+		if nonResourceMerged[key] == nil {
+			nonResourceMerged[key] = map[string]bool{}
+		}
+		nonResourceMerged[key][url] = true
+	}
+	for verbKey, urls := range nonResourceMerged {
+		rules = append(rules, rbacv1.PolicyRule{
+			Verbs:           sortedSlice(splitJoined(verbKey)),
+			NonResourceURLs: sortedSlice(urls),
+		})
+	}
+
+	return rules
+}
@@ -0,0 +1,97 @@
+package rulevalidation
+
+import (
+	"fmt"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type staticRoleGetter map[string]*rbacv1.Role
+
+func (s staticRoleGetter) GetRole(namespace, name string) (*rbacv1.Role, error) {
+	if role, ok := s[namespace+"/"+name]; ok {
+		return role, nil
+	}
+	return nil, fmt.Errorf("role.rbac.authorization.k8s.io %q not found", name)
+}
+
+type staticClusterRoleGetter map[string]*rbacv1.ClusterRole
+
+func (s staticClusterRoleGetter) GetClusterRole(name string) (*rbacv1.ClusterRole, error) {
+	if clusterRole, ok := s[name]; ok {
+		return clusterRole, nil
+	}
+	return nil, fmt.Errorf("clusterrole.rbac.authorization.k8s.io %q not found", name)
+}
+
+func (s staticClusterRoleGetter) ListClusterRoles() ([]*rbacv1.ClusterRole, error) {
+	out := make([]*rbacv1.ClusterRole, 0, len(s))
+	for _, clusterRole := range s {
+		out = append(out, clusterRole)
+	}
+	return out, nil
+}
+
+func TestEffectiveRulesForRole(t *testing.T) {
+	roles := staticRoleGetter{
+		"ns/edit": {Rules: []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+	}
+
+	result := EffectiveRulesFor(roles, staticClusterRoleGetter{}, "ns", rbacv1.RoleRef{Kind: "Role", Name: "edit"})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if len(result.Rules) != 1 || result.Rules[0].Resources[0] != "pods" {
+		t.Fatalf("expected the role's own rules, got %#v", result.Rules)
+	}
+}
+
+func TestEffectiveRulesForMissingRoleReturnsPartialResult(t *testing.T) {
+	result := EffectiveRulesFor(staticRoleGetter{}, staticClusterRoleGetter{}, "ns", rbacv1.RoleRef{Kind: "Role", Name: "missing"})
+	if result.Error == "" {
+		t.Fatal("expected a non-empty Error for a dangling RoleRef")
+	}
+	if result.Rules != nil {
+		t.Fatalf("expected no rules alongside the error, got %#v", result.Rules)
+	}
+}
+
+func TestEffectiveRulesForAggregatedClusterRole(t *testing.T) {
+	clusterRoles := staticClusterRoleGetter{
+		"view-pods": {
+			ObjectMeta: metav1.ObjectMeta{Name: "view-pods", Labels: map[string]string{"rbac.example.com/aggregate-to-view": "true"}},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+		},
+		"view-services": {
+			ObjectMeta: metav1.ObjectMeta{Name: "view-services", Labels: map[string]string{"rbac.example.com/aggregate-to-view": "true"}},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"get"}}},
+		},
+		"view": {
+			ObjectMeta: metav1.ObjectMeta{Name: "view"},
+			AggregationRule: &rbacv1.AggregationRule{
+				ClusterRoleSelectors: []metav1.LabelSelector{
+					{MatchLabels: map[string]string{"rbac.example.com/aggregate-to-view": "true"}},
+				},
+			},
+		},
+	}
+
+	result := EffectiveRulesFor(staticRoleGetter{}, clusterRoles, "", rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"})
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+
+	found := map[string]bool{}
+	for _, rule := range result.Rules {
+		for _, resource := range rule.Resources {
+			found[resource] = true
+		}
+	}
+	for _, want := range []string{"pods", "services"} {
+		if !found[want] {
+			t.Errorf("expected aggregated rules to include resource %q, got %#v", want, result.Rules)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package rulevalidation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// RuleResolver resolves the complete, effective set of PolicyRules a user
+// holds, aggregating every Role/ClusterRole reachable through their
+// RoleBindings/ClusterRoleBindings.
+type RuleResolver interface {
+	RulesFor(actingUser user.Info, namespace string) ([]rbacv1.PolicyRule, error)
+}
+
+// ConfirmNoEscalation is the escalation check a (Cluster)Role(Binding) REST
+// storage calls before admitting a write that would grant more than the
+// acting user holds. That storage lives outside this package (and outside
+// this tree's slice of the repository); callers there are expected to
+// return this function's error as-is rather than re-wrapping it through
+// apierrors.NewForbidden, which would discard Details.Causes.
+//
+// ConfirmNoEscalation verifies that requestedRules is fully covered by the
+// rules the acting user already holds. If it is not, it returns a
+// *kapierrors.StatusError whose Details.Causes enumerates the exact missing
+// (APIGroups, Resources, ResourceNames, Verbs, NonResourceURLs) tuples, so
+// API clients can discover programmatically what grant they are missing
+// instead of having to parse the human-readable message.
+//
+// roleRef, when non-nil, identifies the Role/ClusterRole a RoleBinding or
+// ClusterRoleBinding is attempting to bind; it is nil for a plain Role or
+// ClusterRole whose own Rules are being checked. sources names the
+// Role/ClusterRole(s) requestedRules were drawn from (e.g. the members of an
+// aggregated ClusterRole); it may be nil when the caller does not track
+// provenance. On denial, both are recorded as audit annotations on ctx
+// alongside the missing rules, so the audit event captures exactly what was
+// denied without a client having to parse the forbidden message.
+func ConfirmNoEscalation(ctx context.Context, resolver RuleResolver, actingUser user.Info, namespace string, qualifiedResource schema.GroupResource, name string, roleRef *rbacv1.RoleRef, sources []string, requestedRules []rbacv1.PolicyRule) error {
+	ownedRules, err := resolver.RulesFor(actingUser, namespace)
+	if err != nil {
+		return err
+	}
+
+	missing := Missing(ownedRules, requestedRules)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if missingJSON, err := json.Marshal(missing); err == nil {
+		audit.AddAuditAnnotation(ctx, "authorization.openshift.io/missing-rules", string(missingJSON))
+	}
+	if roleRef != nil {
+		audit.AddAuditAnnotation(ctx, "authorization.openshift.io/role-ref", fmt.Sprintf("%s.%s/%s", roleRef.Kind, roleRef.APIGroup, roleRef.Name))
+	}
+	if len(sources) > 0 {
+		audit.AddAuditAnnotation(ctx, "authorization.openshift.io/rule-sources", strings.Join(sources, ","))
+	}
+
+	status := metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   403,
+		Reason: metav1.StatusReasonForbidden,
+		Message: fmt.Sprintf(
+			"%s %q is forbidden: user %q (groups=%v) is attempting to grant RBAC permissions not currently held:\n%s",
+			qualifiedResource.String(), name, actingUser.GetName(), actingUser.GetGroups(), formatRules(missing)),
+		Details: &metav1.StatusDetails{
+			Name:  name,
+			Group: qualifiedResource.Group,
+			Kind:  qualifiedResource.Resource,
+		},
+	}
+	for _, rule := range missing {
+		status.Details.Causes = append(status.Details.Causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueNotSupported,
+			Message: formatRule(rule),
+			Field:   "rules",
+		})
+	}
+	if roleRef != nil {
+		status.Details.Causes = append(status.Details.Causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueNotSupported,
+			Message: fmt.Sprintf("roleRef: %s.%s/%s", roleRef.Kind, roleRef.APIGroup, roleRef.Name),
+			Field:   "roleRef",
+		})
+	}
+	if len(sources) > 0 {
+		status.Details.Causes = append(status.Details.Causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueNotSupported,
+			Message: fmt.Sprintf("resolved rule sources: %s", strings.Join(sources, ", ")),
+			Field:   "sources",
+		})
+	}
+
+	return &kapierrors.StatusError{ErrStatus: status}
+}
+
+func formatRules(rules []rbacv1.PolicyRule) string {
+	out := ""
+	for i, r := range rules {
+		if i > 0 {
+			out += "\n"
+		}
+		out += formatRule(r)
+	}
+	return out
+}
+
+func formatRule(rule rbacv1.PolicyRule) string {
+	if len(rule.NonResourceURLs) > 0 {
+		return fmt.Sprintf("PolicyRule{NonResourceURLs:%v, Verbs:%v}", rule.NonResourceURLs, rule.Verbs)
+	}
+	return fmt.Sprintf("PolicyRule{APIGroups:%v, Resources:%v, ResourceNames:%v, Verbs:%v}", rule.APIGroups, rule.Resources, rule.ResourceNames, rule.Verbs)
+}
@@ -0,0 +1,180 @@
+package rulevalidation
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+type staticRuleResolver []rbacv1.PolicyRule
+
+func (s staticRuleResolver) RulesFor(user.Info, string) ([]rbacv1.PolicyRule, error) {
+	return []rbacv1.PolicyRule(s), nil
+}
+
+func TestMissingFindsUncoveredVerbResourcePairs(t *testing.T) {
+	owned := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+	}
+	requested := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods", "services"}, Verbs: []string{"get", "list", "delete"}},
+	}
+
+	missing := Missing(owned, requested)
+	if len(missing) == 0 {
+		t.Fatal("expected missing rules")
+	}
+
+	found := map[string]bool{}
+	for _, rule := range missing {
+		for _, r := range rule.Resources {
+			for _, v := range rule.Verbs {
+				found[v+":"+r] = true
+			}
+		}
+	}
+	for _, want := range []string{"delete:pods", "get:services", "list:services", "delete:services"} {
+		if !found[want] {
+			t.Errorf("expected missing set to contain %s, got %#v", want, missing)
+		}
+	}
+	if found["get:pods"] || found["list:pods"] {
+		t.Errorf("did not expect already-owned get/list:pods in missing set, got %#v", missing)
+	}
+}
+
+func TestMissingRespectsWildcards(t *testing.T) {
+	owned := []rbacv1.PolicyRule{
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+	}
+	requested := []rbacv1.PolicyRule{
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"create"}},
+	}
+
+	if missing := Missing(owned, requested); len(missing) != 0 {
+		t.Fatalf("expected wildcard owner to cover everything, got missing %#v", missing)
+	}
+}
+
+func TestMissingDoesNotLetResourceCoverSubresource(t *testing.T) {
+	owned := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+	}
+	requested := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+	}
+
+	missing := Missing(owned, requested)
+	if len(missing) != 1 || missing[0].Resources[0] != "pods/log" {
+		t.Fatalf("expected pods/log to remain missing, got %#v", missing)
+	}
+}
+
+func TestConfirmNoEscalationReturnsStructuredCauses(t *testing.T) {
+	resolver := staticRuleResolver{
+		{APIGroups: []string{""}, Resources: []string{"selfsubjectaccessreviews"}, Verbs: []string{"create"}},
+	}
+	requested := []rbacv1.PolicyRule{
+		{APIGroups: []string{"bear"}, Resources: []string{"pandas"}, Verbs: []string{"hug"}},
+	}
+
+	err := ConfirmNoEscalation(context.Background(), resolver, &user.DefaultInfo{Name: "test-user"}, "", schema.GroupResource{Group: "authorization.openshift.io", Resource: "roles"}, "my-role", nil, nil, requested)
+	if err == nil {
+		t.Fatal("expected an escalation error")
+	}
+
+	statusErr, ok := err.(*kapierrors.StatusError)
+	if !ok {
+		t.Fatalf("expected *kapierrors.StatusError, got %T", err)
+	}
+	if statusErr.ErrStatus.Details == nil || len(statusErr.ErrStatus.Details.Causes) != 1 {
+		t.Fatalf("expected exactly one cause, got %#v", statusErr.ErrStatus.Details)
+	}
+	cause := statusErr.ErrStatus.Details.Causes[0]
+	for _, want := range []string{"bear", "pandas", "hug"} {
+		if !contains(cause.Message, want) {
+			t.Errorf("expected cause message %q to mention %q", cause.Message, want)
+		}
+	}
+}
+
+func TestConfirmNoEscalationAddsRoleRefAndSourceCauses(t *testing.T) {
+	resolver := staticRuleResolver(nil)
+	requested := []rbacv1.PolicyRule{
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+	}
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"}
+
+	err := ConfirmNoEscalation(context.Background(), resolver, &user.DefaultInfo{Name: "test-user"}, "",
+		schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}, "my-binding",
+		&roleRef, []string{"cluster-admin", "cluster-admin-aggregate"}, requested)
+	if err == nil {
+		t.Fatal("expected an escalation error")
+	}
+
+	statusErr, ok := err.(*kapierrors.StatusError)
+	if !ok {
+		t.Fatalf("expected *kapierrors.StatusError, got %T", err)
+	}
+	causesByField := map[string]string{}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		causesByField[cause.Field] = cause.Message
+	}
+	if !contains(causesByField["roleRef"], "cluster-admin") {
+		t.Errorf("expected a roleRef cause mentioning cluster-admin, got %#v", causesByField)
+	}
+	if !contains(causesByField["sources"], "cluster-admin-aggregate") {
+		t.Errorf("expected a sources cause mentioning cluster-admin-aggregate, got %#v", causesByField)
+	}
+}
+
+// TestConfirmNoEscalationRecordsAuditAnnotations verifies the
+// missing-rules/role-ref/rule-sources annotations land on the real
+// k8s.io/apiserver/pkg/audit event when ctx carries an audit context, the
+// way it would on a request that has passed through the apiserver's audit
+// filter before reaching (Cluster)Role(Binding) REST storage.
+func TestConfirmNoEscalationRecordsAuditAnnotations(t *testing.T) {
+	ctx := audit.WithAuditContext(context.Background())
+
+	resolver := staticRuleResolver(nil)
+	requested := []rbacv1.PolicyRule{
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+	}
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"}
+
+	err := ConfirmNoEscalation(ctx, resolver, &user.DefaultInfo{Name: "test-user"}, "",
+		schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}, "my-binding",
+		&roleRef, []string{"cluster-admin"}, requested)
+	if err == nil {
+		t.Fatal("expected an escalation error")
+	}
+
+	auditCtx := audit.AuditContextFrom(ctx)
+	if auditCtx == nil {
+		t.Fatal("expected an audit context to be attached to ctx")
+	}
+	annotations := auditCtx.Event.Annotations
+	if !contains(annotations["authorization.openshift.io/missing-rules"], "\"*\"") {
+		t.Errorf("expected a missing-rules annotation mentioning the requested rule, got %#v", annotations)
+	}
+	if !contains(annotations["authorization.openshift.io/role-ref"], "cluster-admin") {
+		t.Errorf("expected a role-ref annotation mentioning cluster-admin, got %#v", annotations)
+	}
+	if !contains(annotations["authorization.openshift.io/rule-sources"], "cluster-admin") {
+		t.Errorf("expected a rule-sources annotation mentioning cluster-admin, got %#v", annotations)
+	}
+}
+
+func contains(haystack, needle string) bool {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if haystack[i:i+len(needle)] == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package rulevalidation
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// DefaultPrivilegedGroup is the group EscalationPolicy exempts from
+// escalation checks when no explicit privileged-group list is configured,
+// mirroring the upstream RBAC registry's default super-user group.
+const DefaultPrivilegedGroup = "system:masters"
+
+// EscalationPolicy names the callers that bypass ConfirmNoEscalation (and
+// the bind check in EscalationCheck) entirely: a single configured
+// super-user, and any member of a configured set of privileged groups. The
+// zero value exempts nobody.
+type EscalationPolicy struct {
+	SuperUser        string
+	PrivilegedGroups []string
+}
+
+// NewDefaultEscalationPolicy returns the EscalationPolicy servers should use
+// absent explicit configuration: no super-user, and DefaultPrivilegedGroup
+// as the sole privileged group.
+func NewDefaultEscalationPolicy() EscalationPolicy {
+	return EscalationPolicy{PrivilegedGroups: []string{DefaultPrivilegedGroup}}
+}
+
+// IsPrivileged reports whether actingUser should bypass escalation checks
+// under p.
+func (p EscalationPolicy) IsPrivileged(actingUser user.Info) bool {
+	if len(p.SuperUser) > 0 && actingUser.GetName() == p.SuperUser {
+		return true
+	}
+	if len(p.PrivilegedGroups) == 0 {
+		return false
+	}
+	groups := sets.NewString(actingUser.GetGroups()...)
+	for _, g := range p.PrivilegedGroups {
+		if groups.Has(g) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package rulevalidation
+
+import (
+	"testing"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func TestEscalationPolicyIsPrivileged(t *testing.T) {
+	policy := EscalationPolicy{SuperUser: "admin", PrivilegedGroups: []string{DefaultPrivilegedGroup}}
+
+	cases := []struct {
+		name string
+		user user.Info
+		want bool
+	}{
+		{"configured super-user", &user.DefaultInfo{Name: "admin"}, true},
+		{"member of default privileged group", &user.DefaultInfo{Name: "someone", Groups: []string{DefaultPrivilegedGroup}}, true},
+		{"unrelated user", &user.DefaultInfo{Name: "someone-else"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := policy.IsPrivileged(c.user); got != c.want {
+				t.Errorf("IsPrivileged(%+v) = %v, want %v", c.user, got, c.want)
+			}
+		})
+	}
+}
+
+func TestEscalationPolicyZeroValueExemptsNobody(t *testing.T) {
+	if (EscalationPolicy{}).IsPrivileged(&user.DefaultInfo{Name: "anyone", Groups: []string{DefaultPrivilegedGroup}}) {
+		t.Fatal("expected the zero-value policy to exempt nobody, even system:masters")
+	}
+}
+
+func TestNewDefaultEscalationPolicyExemptsSystemMasters(t *testing.T) {
+	policy := NewDefaultEscalationPolicy()
+	if !policy.IsPrivileged(&user.DefaultInfo{Name: "someone", Groups: []string{DefaultPrivilegedGroup}}) {
+		t.Fatal("expected the default policy to exempt system:masters")
+	}
+	if policy.IsPrivileged(&user.DefaultInfo{Name: "someone"}) {
+		t.Fatal("expected the default policy to not exempt an arbitrary user")
+	}
+}
@@ -0,0 +1,32 @@
+package rulevalidation
+
+import (
+	"sort"
+	"strings"
+)
+
+func sortedSlice(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// sortedJoin produces a stable string key for a verb set so that two equal
+// sets always coalesce to the same map key regardless of insertion order.
+func sortedJoin(set map[string]bool) string {
+	return strings.Join(sortedSlice(set), ",")
+}
+
+func splitJoined(joined string) map[string]bool {
+	out := map[string]bool{}
+	if len(joined) == 0 {
+		return out
+	}
+	for _, v := range strings.Split(joined, ",") {
+		out[v] = true
+	}
+	return out
+}
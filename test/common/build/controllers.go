@@ -1,6 +1,7 @@
 package build
 
 import (
+	"context"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -9,11 +10,14 @@ import (
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	watchapi "k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/retry"
 
 	buildv1 "github.com/openshift/api/build/v1"
@@ -59,8 +63,10 @@ type testingT interface {
 	Skipped() bool
 }
 
-func mockBuild() *buildv1.Build {
-	return &buildv1.Build{
+// mockBuild returns a mock Build using DockerStrategy against a Git source,
+// or whatever opts override that with.
+func mockBuild(opts ...MockBuildOption) *buildv1.Build {
+	b := &buildv1.Build{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: "mock-build",
 			Labels: map[string]string{
@@ -90,71 +96,127 @@ func mockBuild() *buildv1.Build {
 			},
 		},
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 func RunBuildControllerTest(t testingT, buildClient buildv1clienttyped.BuildsGetter, kClientset kubernetes.Interface, ns string) {
-	// Setup an error channel
-	errChan := make(chan error) // go routines will send a message on this channel if an error occurs. Once this happens the test is over
+	for _, strategy := range StandardStrategyCases() {
+		runBuildControllerTestCase(t, buildClient, kClientset, ns, strategy)
+	}
+}
 
+func runBuildControllerTestCase(t testingT, buildClient buildv1clienttyped.BuildsGetter, kClientset kubernetes.Interface, ns string, strategy buildStrategyCase) {
 	// Create a build
-	b, err := buildClient.Builds(ns).Create(mockBuild())
+	b, err := buildClient.Builds(ns).Create(mockBuild(strategy.Options...))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Start watching builds for New -> Pending transition
-	buildWatch, err := buildClient.Builds(ns).Watch(metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", b.Name).String(), ResourceVersion: b.ResourceVersion})
-	if err != nil {
-		t.Fatal(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker := NewBuildPodTracker(kClientset, buildClient, ns, b.Name, buildutil.GetBuildPodName(b))
+	tracker.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), tracker.PodSynced, tracker.BuildSynced) {
+		t.Fatal("timed out waiting for build pod tracker caches to sync")
 	}
-	defer buildWatch.Stop()
-	buildModifiedCount := int32(0)
-	go func() {
-		for e := range buildWatch.ResultChan() {
-			if e.Type != watchapi.Modified {
-				errChan <- fmt.Errorf("received an unexpected event of type: %s with object: %#v", e.Type, e.Object)
-			}
-			build, ok := e.Object.(*buildv1.Build)
-			if !ok {
-				errChan <- fmt.Errorf("received something other than build: %#v", e.Object)
-				break
-			}
-			// If unexpected status, throw error
-			if build.Status.Phase != buildv1.BuildPhasePending && build.Status.Phase != buildv1.BuildPhaseNew {
-				errChan <- fmt.Errorf("received unexpected build status: %s", build.Status.Phase)
-				break
-			}
-			atomic.AddInt32(&buildModifiedCount, 1)
+
+	if strategy.ExpectInvalid {
+		assertBuildFailureReason(t, kClientset, ns, b, strategy.ExpectedFailReason)
+		return
+	}
+
+	var buildModifiedCount int32
+	tracker.OnBuildPhase(func(phase buildv1.BuildPhase) {
+		// If unexpected status, throw error
+		if phase != buildv1.BuildPhasePending && phase != buildv1.BuildPhaseNew {
+			t.Errorf("%s: received unexpected build status: %s", strategy.Name, phase)
+			return
 		}
-	}()
+		atomic.AddInt32(&buildModifiedCount, 1)
+	})
+
+	var podAddedCount int32
+	tracker.OnPodPhase(func(corev1.PodPhase) {
+		atomic.AddInt32(&podAddedCount, 1)
+	})
+
+	<-time.After(BuildControllerTestWait)
+
+	if atomic.LoadInt32(&buildModifiedCount) < 1 {
+		t.Errorf("%s: The build was modified an unexpected number of times. Got: %d, Expected: >= 1", strategy.Name, buildModifiedCount)
+	}
+	if atomic.LoadInt32(&podAddedCount) < 1 {
+		t.Errorf("%s: The build pod was never observed by the tracker", strategy.Name)
+	}
 
-	// Watch build pods as they are created
-	podWatch, err := kClientset.CoreV1().Pods(ns).Watch(metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name",
-		buildutil.GetBuildPodName(b)).String()})
+	assertBuildPodMatchesStrategy(t, kClientset, ns, buildutil.GetBuildPodName(b), strategy)
+}
+
+// assertBuildPodMatchesStrategy checks that the build pod the controller
+// created for a build has the image, env and privileged flag the strategy
+// it was built from expects. A zero-value field on strategy means "don't
+// assert this".
+func assertBuildPodMatchesStrategy(t testingT, kClientset kubernetes.Interface, ns, podName string, strategy buildStrategyCase) {
+	pod, err := kClientset.CoreV1().Pods(ns).Get(podName, metav1.GetOptions{})
 	if err != nil {
-		t.Fatal(err)
+		t.Errorf("%s: could not get build pod %s: %v", strategy.Name, podName, err)
+		return
 	}
-	defer podWatch.Stop()
-	podAddedCount := int32(0)
-	go func() {
-		for e := range podWatch.ResultChan() {
-			// Look for creation events
-			if e.Type == watchapi.Added {
-				atomic.AddInt32(&podAddedCount, 1)
+	if len(pod.Spec.Containers) == 0 {
+		t.Errorf("%s: build pod %s has no containers", strategy.Name, podName)
+		return
+	}
+	container := pod.Spec.Containers[0]
+	if strategy.ExpectedImage != "" && container.Image != strategy.ExpectedImage {
+		t.Errorf("%s: expected build pod image %s, got %s", strategy.Name, strategy.ExpectedImage, container.Image)
+	}
+	if strategy.Privileged {
+		if container.SecurityContext == nil || container.SecurityContext.Privileged == nil || !*container.SecurityContext.Privileged {
+			t.Errorf("%s: expected build pod container to run privileged", strategy.Name)
+		}
+	}
+	for _, expected := range strategy.ExpectedEnv {
+		found := false
+		for _, actual := range container.Env {
+			if actual.Name == expected.Name && actual.Value == expected.Value {
+				found = true
+				break
 			}
 		}
-	}()
+		if !found {
+			t.Errorf("%s: expected build pod env %s=%s not found in %v", strategy.Name, expected.Name, expected.Value, container.Env)
+		}
+	}
+}
 
-	select {
-	case err := <-errChan:
-		t.Errorf("Error: %v", err)
-	case <-time.After(BuildControllerTestWait):
-		if atomic.LoadInt32(&buildModifiedCount) < 1 {
-			t.Errorf("The build was modified an unexpected number of times. Got: %d, Expected: >= 1", buildModifiedCount)
+// assertBuildFailureReason polls the build's events until one with reason
+// is recorded against it, failing the test if none appears within
+// BuildControllersWatchTimeout. The build's phase is not checked.
+func assertBuildFailureReason(t testingT, kClientset kubernetes.Interface, ns string, b *buildv1.Build, reason string) {
+	found := false
+	err := wait.Poll(time.Second, BuildControllersWatchTimeout, func() (bool, error) {
+		current, err := kClientset.CoreV1().Events(ns).Search(legacyscheme.Scheme, b)
+		if err != nil {
+			return false, fmt.Errorf("error getting build events: %v", err)
 		}
-		if atomic.LoadInt32(&podAddedCount) != 1 {
-			t.Errorf("The build pod was created an unexpected number of times. Got: %d, Expected: 1", podAddedCount)
+		for _, event := range current.Items {
+			if event.Reason == reason {
+				found = true
+				return true, nil
+			}
 		}
+		return false, nil
+	})
+	if err != nil {
+		t.Errorf("invalid strategy: %v", err)
+		return
+	}
+	if !found {
+		t.Errorf("expected to find a %s event on build %s/%s", reason, b.Namespace, b.Name)
 	}
 }
 
@@ -206,141 +268,94 @@ func RunBuildControllerPodSyncTest(t testingT, buildClient buildv1clienttyped.Bu
 			},
 		},
 	}
+	strategies := StandardStrategyCases()
 	for _, test := range tests {
-		// Setup communications channels
-		podReadyChan := make(chan *corev1.Pod) // Will receive a value when a build pod is ready
-		errChan := make(chan error)            // Will receive a value when an error occurs
-
-		// Create a build
-		b, err := buildClient.Builds(ns).Create(mockBuild())
-		if err != nil {
-			t.Fatal(err)
-		}
+		for _, strategy := range strategies {
+			if strategy.ExpectInvalid {
+				// Invalid builds never reach a running pod, so they don't
+				// exercise the pod-phase -> build-phase sync under test
+				// here; RunBuildControllerTest covers them instead.
+				continue
+			}
+			name := fmt.Sprintf("%s/%s", test.Name, strategy.Name)
 
-		// Watch build pod for transition to pending
-		podWatch, err := kClient.CoreV1().Pods(ns).Watch(metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name",
-			buildutil.GetBuildPodName(b)).String()})
-		if err != nil {
-			t.Fatal(err)
-		}
-		go func() {
-			for e := range podWatch.ResultChan() {
-				pod, ok := e.Object.(*corev1.Pod)
-				if !ok {
-					t.Fatalf("%s: unexpected object received: %#v\n", test.Name, e.Object)
-				}
-				klog.Infof("pod watch event received for pod %s/%s: %v, pod phase: %v", pod.Namespace, pod.Name, e.Type, pod.Status.Phase)
-				if pod.Status.Phase == corev1.PodPending {
-					podReadyChan <- pod
-					break
-				}
+			// Create a build
+			b, err := buildClient.Builds(ns).Create(mockBuild(strategy.Options...))
+			if err != nil {
+				t.Fatal(err)
 			}
-		}()
+			podName := buildutil.GetBuildPodName(b)
 
-		var pod *corev1.Pod
-		select {
-		case pod = <-podReadyChan:
-			if pod.Status.Phase != corev1.PodPending {
-				t.Errorf("Got wrong pod phase: %s", pod.Status.Phase)
-				podWatch.Stop()
+			ctx, cancel := context.WithCancel(context.Background())
+
+			tracker := NewBuildPodTracker(kClient, buildClient, ns, b.Name, podName)
+			tracker.Start(ctx.Done())
+			if !cache.WaitForCacheSync(ctx.Done(), tracker.PodSynced, tracker.BuildSynced) {
+				t.Errorf("%s: timed out waiting for build pod tracker caches to sync", name)
+				cancel()
 				continue
 			}
 
-		case <-time.After(BuildControllersWatchTimeout):
-			t.Errorf("Timed out waiting for build pod to be ready")
-			podWatch.Stop()
-			continue
-		}
-		podWatch.Stop()
-
-		for _, state := range test.States {
-			if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-				// Update pod state and verify that corresponding build state happens accordingly
-				pod, err := kClient.CoreV1().Pods(ns).Get(pod.Name, metav1.GetOptions{})
-				if err != nil {
-					return err
-				}
-				if pod.Status.Phase == state.PodPhase {
-					return fmt.Errorf("another client altered the pod phase to %s: %#v", state.PodPhase, pod)
-				}
-				pod.Status.Phase = state.PodPhase
-				if pod.Status.Phase == corev1.PodSucceeded {
-					pod.Status.ContainerStatuses = []corev1.ContainerStatus{
-						{
-							Name: "container",
-							State: corev1.ContainerState{
-								Terminated: &corev1.ContainerStateTerminated{
-									ExitCode: 0,
-								},
-							},
-						},
+			podPending := make(chan struct{}, 1)
+			tracker.OnPodPhase(func(phase corev1.PodPhase) {
+				klog.Infof("pod phase observed for pod %s/%s: %v", ns, podName, phase)
+				if phase == corev1.PodPending {
+					select {
+					case podPending <- struct{}{}:
+					default:
 					}
 				}
-				_, err = kClient.CoreV1().Pods(ns).UpdateStatus(pod)
-				return err
-			}); err != nil {
-				t.Fatal(err)
+			})
+
+			select {
+			case <-podPending:
+			case <-time.After(BuildControllersWatchTimeout):
+				t.Errorf("%s: Timed out waiting for build pod to be ready", name)
+				cancel()
+				continue
 			}
 
-			shouldContinue := func() bool {
-				buildWatch, err := buildClient.Builds(ns).Watch(metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", b.Name).String(), ResourceVersion: b.ResourceVersion})
-				if err != nil {
-					t.Fatal(err)
-				}
-				defer buildWatch.Stop()
-
-				stateReached := make(chan struct{})
-				go func() {
-					done := false
-					for e := range buildWatch.ResultChan() {
-						var ok bool
-						b, ok = e.Object.(*buildv1.Build)
-						if !ok {
-							errChan <- fmt.Errorf("unexpected object received: %#v", e.Object)
-							return
-						}
-						klog.Infof("build watch event received for build %s/%s: %v, build phase: %v", b.Namespace, b.Name, e.Type, b.Status.Phase)
-						if e.Type != watchapi.Modified {
-							errChan <- fmt.Errorf("unexpected event received: %s, object: %#v", e.Type, e.Object)
-							return
-						}
-						if done && b.Status.Phase != state.BuildPhase {
-							errChan <- fmt.Errorf("build %s/%s transitioned to new state (%s) after reaching desired state", b.Namespace, b.Name, b.Status.Phase)
-							return
-						}
-						if b.Status.Phase == state.BuildPhase {
-							done = true
-							stateReached <- struct{}{}
+			for _, state := range test.States {
+				if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+					// Update pod state and verify that corresponding build state happens accordingly
+					pod, err := kClient.CoreV1().Pods(ns).Get(podName, metav1.GetOptions{})
+					if err != nil {
+						return err
+					}
+					if pod.Status.Phase == state.PodPhase {
+						return fmt.Errorf("another client altered the pod phase to %s: %#v", state.PodPhase, pod)
+					}
+					pod.Status.Phase = state.PodPhase
+					if pod.Status.Phase == corev1.PodSucceeded {
+						pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+							{
+								Name: "container",
+								State: corev1.ContainerState{
+									Terminated: &corev1.ContainerStateTerminated{
+										ExitCode: 0,
+									},
+								},
+							},
 						}
 					}
-				}()
-
-				select {
-				case err := <-errChan:
-					t.Errorf("%s: Error %v", test.Name, err)
-					return false
-				case <-time.After(BuildControllerTestTransitionTimeout):
-					t.Errorf("%s: Timed out waiting for build %s/%s to reach state %s. Current state: %s", test.Name, b.Namespace, b.Name, state.BuildPhase, b.Status.Phase)
-					return false
-				case <-stateReached:
-					klog.Infof("%s: build %s/%s reached desired state of %s", test.Name, b.Namespace, b.Name, state.BuildPhase)
+					_, err = kClient.CoreV1().Pods(ns).UpdateStatus(pod)
+					return err
+				}); err != nil {
+					t.Fatal(err)
 				}
 
-				// After state is reached, continue waiting some time to check for unexpected transitions
-				select {
-				case err := <-errChan:
-					t.Errorf("%s: Error %v", test.Name, err)
-					return false
-
-				case <-time.After(BuildControllerTestWait):
-					// After waiting for a set time, if no other state is reached, continue to wait for next state transition
-					return true
+				if _, err := WaitForBuildPhases(ctx, buildClient.Builds(ns), b.Name, []buildv1.BuildPhase{state.BuildPhase}, BuildControllerTestTransitionTimeout); err != nil {
+					t.Errorf("%s: %v", name, err)
+					break
 				}
-			}()
+				klog.Infof("%s: build %s/%s reached desired state of %s", name, ns, b.Name, state.BuildPhase)
+			}
 
-			if !shouldContinue {
-				break
+			if len(test.States) > 0 {
+				assertBuildPodMatchesStrategy(t, kClient, ns, podName, strategy)
 			}
+
+			cancel()
 		}
 	}
 }
@@ -358,6 +373,50 @@ func waitForWatch(t testingT, name string, w watchapi.Interface) *watchapi.Event
 	}
 }
 
+// drainLastEvent consumes every event w delivers for the duration of
+// timeout and returns the last one observed (nil if none arrived). It
+// replaces the WaitLoop-style "keep reassigning event until a timer fires"
+// blocks that used to appear inline wherever a test cared only about a
+// watch's state once it settled, not the sequence of updates leading there.
+func drainLastEvent(t testingT, name string, w watchapi.Interface, timeout time.Duration) *watchapi.Event {
+	deadline := time.After(timeout)
+	var last *watchapi.Event
+	for {
+		select {
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				t.Fatalf("Channel closed waiting for watch: %s", name)
+			}
+			last = &e
+		case <-deadline:
+			return last
+		}
+	}
+}
+
+// drainUntilTimeout discards every event delivered on w1 and w2 for the
+// duration of timeout, failing the test if either closes early. It
+// replaces the WaitLoop2-style inline two-watch drain that used to appear
+// between triggering one build and waiting on the next, so a leftover
+// event from the first build isn't mistaken for one from the second.
+func drainUntilTimeout(t testingT, timeout time.Duration, w1, w2 watchapi.Interface) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case _, ok := <-w1.ResultChan():
+			if !ok {
+				t.Fatalf("Channel closed waiting for watch: drain before next trigger")
+			}
+		case _, ok := <-w2.ResultChan():
+			if !ok {
+				t.Fatalf("Channel closed waiting for watch: drain before next trigger")
+			}
+		case <-deadline:
+			return
+		}
+	}
+}
+
 func RunImageChangeTriggerTest(t testingT, clusterAdminBuildClient buildv1clienttyped.BuildV1Interface, clusterAdminImageClient imagev1clienttyped.ImageV1Interface, ns string) {
 	const (
 		tag              = "latest"
@@ -371,10 +430,38 @@ func RunImageChangeTriggerTest(t testingT, clusterAdminBuildClient buildv1client
 	imageStreamMapping := mockImageStreamMapping(imageStream.Name, "someimage", tag, registryHostname+"/openshift/test-image-trigger:"+tag)
 
 	config := imageChangeBuildConfig(ns, "sti-imagestreamtag", stiStrategy("ImageStreamTag", streamName+":"+tag))
+
+	// The imagechangecontroller looks up this BuildConfig from its own
+	// informer cache while processing the imagestream update event below;
+	// created too early relative to that cache, the trigger is missed
+	// entirely. Run an equivalent informer here, scoped to this one
+	// BuildConfig, and wait for it to sync before creating the
+	// ImageStreamMapping that fires the trigger, the same
+	// cache.WaitForCacheSync pattern BuildPodTracker uses, instead of
+	// guessing how long the controller's own cache takes to warm with a
+	// fixed sleep.
+	configListWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", config.Name).String()
+			return clusterAdminBuildClient.BuildConfigs(ns).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watchapi.Interface, error) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", config.Name).String()
+			return clusterAdminBuildClient.BuildConfigs(ns).Watch(options)
+		},
+	}
+	configInformer := cache.NewSharedIndexInformer(configListWatch, &buildv1.BuildConfig{}, 0, cache.Indexers{})
+	configSyncCtx, stopConfigInformer := context.WithCancel(context.Background())
+	defer stopConfigInformer()
+	go configInformer.Run(configSyncCtx.Done())
+
 	_, err := clusterAdminBuildClient.BuildConfigs(ns).Create(config)
 	if err != nil {
 		t.Fatalf("Couldn't create BuildConfig: %v", err)
 	}
+	if !cache.WaitForCacheSync(configSyncCtx.Done(), configInformer.HasSynced) {
+		t.Fatal("timed out waiting for buildconfig informer cache to sync")
+	}
 
 	watch, err := clusterAdminBuildClient.Builds(ns).Watch(metav1.ListOptions{})
 	if err != nil {
@@ -393,9 +480,6 @@ func RunImageChangeTriggerTest(t testingT, clusterAdminBuildClient buildv1client
 		t.Fatalf("Couldn't create ImageStream: %v", err)
 	}
 
-	// give the imagechangecontroller's buildconfig cache time to be updated with the buildconfig object
-	// so it doesn't get a miss when looking up the BC while processing the imagestream update event.
-	time.Sleep(10 * time.Second)
 	_, err = clusterAdminImageClient.ImageStreamMappings(ns).Create(imageStreamMapping)
 	if err != nil {
 		t.Fatalf("Couldn't create Image: %v", err)
@@ -433,20 +517,7 @@ func RunImageChangeTriggerTest(t testingT, clusterAdminBuildClient buildv1client
 	}
 
 	// wait for build config to be updated
-	timeout := time.After(BuildControllerTestWait)
-WaitLoop:
-	for {
-		select {
-		case e, ok := <-watch2.ResultChan():
-			if !ok {
-				t.Fatalf("Channel closed waiting for watch: build config update in WaitLoop")
-			}
-			event = &e
-			continue
-		case <-timeout:
-			break WaitLoop
-		}
-	}
+	event = drainLastEvent(t, "build config update", watch2, BuildControllerTestWait)
 	updatedConfig := event.Object.(*buildv1.BuildConfig)
 	if err != nil {
 		t.Fatalf("Couldn't get BuildConfig: %v", err)
@@ -457,24 +528,7 @@ WaitLoop:
 	}
 
 	// clear out the build/buildconfig watches before triggering a new build
-	timeout = time.After(60 * time.Second)
-WaitLoop2:
-	for {
-		select {
-		case _, ok := <-watch.ResultChan():
-			if !ok {
-				t.Fatalf("Channel closed waiting for watch: build update in WaitLoop2")
-			}
-			continue
-		case _, ok := <-watch2.ResultChan():
-			if !ok {
-				t.Fatalf("Channel closed waiting for watch: build config update in WaitLoop2")
-			}
-			continue
-		case <-timeout:
-			break WaitLoop2
-		}
-	}
+	drainUntilTimeout(t, 60*time.Second, watch, watch2)
 
 	// trigger a build by posting a new image
 	if _, err := clusterAdminImageClient.ImageStreamMappings(ns).Create(&imagev1.ImageStreamMapping{
@@ -521,20 +575,7 @@ WaitLoop2:
 		t.Fatalf("Expected build with label %s=%s from build config got %s=%s", "testlabel", "testvalue", "testlabel", newBuild.Labels["testlabel"])
 	}
 
-	timeout = time.After(BuildControllerTestWait)
-WaitLoop3:
-	for {
-		select {
-		case e, ok := <-watch2.ResultChan():
-			if !ok {
-				t.Fatalf("Channel closed waiting for watch: build config update in WaitLoop3")
-			}
-			event = &e
-			continue
-		case <-timeout:
-			break WaitLoop3
-		}
-	}
+	event = drainLastEvent(t, "build config update", watch2, BuildControllerTestWait)
 	updatedConfig = event.Object.(*buildv1.BuildConfig)
 	if e, a := registryHostname+"/openshift/test-image-trigger:ref-2-random", updatedConfig.Spec.Triggers[0].ImageChange.LastTriggeredImageID; e != a {
 		t.Errorf("unexpected trigger id: expected %v, got %v", e, a)
@@ -542,133 +583,53 @@ WaitLoop3:
 }
 
 func RunBuildDeleteTest(t testingT, clusterAdminClient buildv1clienttyped.BuildsGetter, clusterAdminKubeClientset kubernetes.Interface, ns string) {
-	buildWatch, err := clusterAdminClient.Builds(ns).Watch(metav1.ListOptions{})
-	if err != nil {
-		t.Fatalf("Couldn't subscribe to Builds %v", err)
-	}
-	defer buildWatch.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	_, err = clusterAdminClient.Builds(ns).Create(mockBuild())
-	if err != nil {
-		t.Fatalf("Couldn't create Build: %v", err)
-	}
+	tracker := NewBuildLifecycleTracker(clusterAdminClient, clusterAdminKubeClientset, ns)
+	tracker.Start(ctx.Done())
 
-	podWatch, err := clusterAdminKubeClientset.CoreV1().Pods(ns).Watch(metav1.ListOptions{})
+	newBuild, err := clusterAdminClient.Builds(ns).Create(mockBuild())
 	if err != nil {
-		t.Fatalf("Couldn't subscribe to Pods %v", err)
-	}
-	defer podWatch.Stop()
-
-	// wait for initial build event from the creation of the imagerepo with tag latest
-	event := waitForWatch(t, "initial build added", buildWatch)
-	if e, a := watchapi.Added, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
+		t.Fatalf("Couldn't create Build: %v", err)
 	}
-	newBuild := event.Object.(*buildv1.Build)
 
-	// initial pod creation for build
-	event = waitForWatch(t, "build pod created", podWatch)
-	if e, a := watchapi.Added, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
+	if err := tracker.WaitForPodEvent(newBuild.Name, watchapi.Added, BuildControllersWatchTimeout); err != nil {
+		t.Fatalf("timed out waiting for build pod to be created: %v", err)
 	}
 
 	clusterAdminClient.Builds(ns).Delete(newBuild.Name, nil)
 
-	event = waitForWatchType(t, "pod deleted due to build deleted", podWatch, watchapi.Deleted)
-	if e, a := watchapi.Deleted, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
-	}
-	pod := event.Object.(*corev1.Pod)
-	if expected := buildutil.GetBuildPodName(newBuild); pod.Name != expected {
-		t.Fatalf("Expected pod %s to be deleted, but pod %s was deleted", expected, pod.Name)
-	}
-
-}
-
-// waitForWatchType tolerates receiving 3 events before failing while watching for a particular event
-// type.
-func waitForWatchType(t testingT, name string, w watchapi.Interface, expect watchapi.EventType) *watchapi.Event {
-	tries := 3
-	for i := 0; i < tries; i++ {
-		select {
-		case e := <-w.ResultChan():
-			if e.Type != expect {
-				continue
-			}
-			return &e
-		case <-time.After(BuildControllersWatchTimeout):
-			t.Fatalf("Timed out waiting for watch: %s", name)
-			return nil
-		}
+	if err := tracker.WaitForPodEvent(newBuild.Name, watchapi.Deleted, BuildControllersWatchTimeout); err != nil {
+		t.Fatalf("timed out waiting for pod %s to be deleted due to build deleted: %v", buildPodName(newBuild.Name), err)
 	}
-	t.Fatalf("Waited for a %v event with %d tries but never received one", expect, tries)
-	return nil
 }
 
 func RunBuildRunningPodDeleteTest(t testingT, clusterAdminClient buildv1clienttyped.BuildsGetter, clusterAdminKubeClientset kubernetes.Interface, ns string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	buildWatch, err := clusterAdminClient.Builds(ns).Watch(metav1.ListOptions{})
-	if err != nil {
-		t.Fatalf("Couldn't subscribe to Builds %v", err)
-	}
-	defer buildWatch.Stop()
+	tracker := NewBuildLifecycleTracker(clusterAdminClient, clusterAdminKubeClientset, ns)
+	tracker.Start(ctx.Done())
 
-	_, err = clusterAdminClient.Builds(ns).Create(mockBuild())
+	newBuild, err := clusterAdminClient.Builds(ns).Create(mockBuild())
 	if err != nil {
 		t.Fatalf("Couldn't create Build: %v", err)
 	}
-
-	podWatch, err := clusterAdminKubeClientset.CoreV1().Pods(ns).Watch(metav1.ListOptions{})
-	if err != nil {
-		t.Fatalf("Couldn't subscribe to Pods %v", err)
-	}
-	defer podWatch.Stop()
-
-	// wait for initial build event from the creation of the imagerepo with tag latest
-	event := waitForWatch(t, "initial build added", buildWatch)
-	if e, a := watchapi.Added, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
-	}
-	newBuild := event.Object.(*buildv1.Build)
 	buildName := newBuild.Name
-	podName := newBuild.Name + "-build"
 
-	// initial pod creation for build
-	for {
-		event = waitForWatch(t, "build pod created", podWatch)
-		newPod := event.Object.(*corev1.Pod)
-		if newPod.Name == podName {
-			break
-		}
-	}
-	if e, a := watchapi.Added, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
+	if err := tracker.WaitForPhase(buildName, buildv1.BuildPhasePending, BuildControllersWatchTimeout); err != nil {
+		t.Fatalf("waiting for build to be marked pending: %v", err)
 	}
 
-	// throw away events from other builds, we only care about the new build
-	// we just triggered
-	for {
-		event = waitForWatch(t, "build updated to pending", buildWatch)
-		newBuild = event.Object.(*buildv1.Build)
-		if newBuild.Name == buildName {
-			break
-		}
-	}
-	if e, a := watchapi.Modified, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
-	}
-	if newBuild.Status.Phase != buildv1.BuildPhasePending {
-		t.Fatalf("expected build status to be marked pending, but was marked %s", newBuild.Status.Phase)
+	clusterAdminKubeClientset.CoreV1().Pods(ns).Delete(buildPodName(buildName), metav1.NewDeleteOptions(0))
+	if err := tracker.WaitForPhase(buildName, buildv1.BuildPhaseError, BuildControllersWatchTimeout); err != nil {
+		t.Fatalf("waiting for build to be marked error: %v", err)
 	}
 
-	clusterAdminKubeClientset.CoreV1().Pods(ns).Delete(buildutil.GetBuildPodName(newBuild), metav1.NewDeleteOptions(0))
-	event = waitForWatch(t, "build updated to error", buildWatch)
-	if e, a := watchapi.Modified, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
-	}
-	newBuild = event.Object.(*buildv1.Build)
-	if newBuild.Status.Phase != buildv1.BuildPhaseError {
-		t.Fatalf("expected build status to be marked error, but was marked %s", newBuild.Status.Phase)
+	newBuild, err = clusterAdminClient.Builds(ns).Get(buildName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting build %s: %v", buildName, err)
 	}
 
 	foundFailed := false
@@ -702,68 +663,137 @@ func RunBuildRunningPodDeleteTest(t testingT, clusterAdminClient buildv1clientty
 	}
 }
 
-func RunBuildCompletePodDeleteTest(t testingT, clusterAdminClient buildv1clienttyped.BuildsGetter, clusterAdminKubeClientset kubernetes.Interface, ns string) {
-
-	buildWatch, err := clusterAdminClient.Builds(ns).Watch(metav1.ListOptions{})
+// RunBuildCancelTest verifies that setting spec.cancelled on a running
+// build causes the controller to tear down the build pod and mark the
+// build Cancelled, and that a BuildCancelledEventReason event is recorded
+// against it.
+func RunBuildCancelTest(t testingT, buildClient buildv1clienttyped.BuildsGetter, kClient kubernetes.Interface, ns string) {
+	b, err := buildClient.Builds(ns).Create(mockBuild())
 	if err != nil {
-		t.Fatalf("Couldn't subscribe to Builds %v", err)
+		t.Fatal(err)
 	}
-	defer buildWatch.Stop()
+	podName := buildutil.GetBuildPodName(b)
 
-	_, err = clusterAdminClient.Builds(ns).Create(mockBuild())
-	if err != nil {
-		t.Fatalf("Couldn't create Build: %v", err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker := NewBuildPodTracker(kClient, buildClient, ns, b.Name, podName)
+	tracker.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), tracker.PodSynced, tracker.BuildSynced) {
+		t.Fatal("timed out waiting for build pod tracker caches to sync")
 	}
 
-	podWatch, err := clusterAdminKubeClientset.CoreV1().Pods(ns).Watch(metav1.ListOptions{})
-	if err != nil {
-		t.Fatalf("Couldn't subscribe to Pods %v", err)
+	podRunning := make(chan struct{}, 1)
+	tracker.OnPodPhase(func(phase corev1.PodPhase) {
+		if phase == corev1.PodRunning {
+			select {
+			case podRunning <- struct{}{}:
+			default:
+			}
+		}
+	})
+
+	select {
+	case <-podRunning:
+	case <-time.After(BuildControllersWatchTimeout):
+		t.Fatal("timed out waiting for build pod to be running")
 	}
-	defer podWatch.Stop()
 
-	// wait for initial build event from the creation of the imagerepo with tag latest
-	event := waitForWatch(t, "initial build added", buildWatch)
-	if e, a := watchapi.Added, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		build, err := buildClient.Builds(ns).Get(b.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		build.Spec.Cancelled = true
+		_, err = buildClient.Builds(ns).Update(build)
+		return err
+	}); err != nil {
+		t.Fatalf("error cancelling build: %v", err)
 	}
-	newBuild := event.Object.(*buildv1.Build)
 
-	// initial pod creation for build
-	event = waitForWatch(t, "build pod created", podWatch)
-	if e, a := watchapi.Added, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
+	cancelled, err := WaitForBuildPhases(ctx, buildClient.Builds(ns), b.Name, []buildv1.BuildPhase{buildv1.BuildPhaseCancelled}, BuildControllersWatchTimeout)
+	if err != nil {
+		t.Fatalf("waiting for build to be marked cancelled: %v", err)
 	}
 
-	event = waitForWatch(t, "build updated to pending", buildWatch)
-	if e, a := watchapi.Modified, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
+	if err := wait.Poll(time.Second, BuildControllersWatchTimeout, func() (bool, error) {
+		_, err := kClient.CoreV1().Pods(ns).Get(podName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, nil
+	}); err != nil {
+		t.Fatalf("timed out waiting for build pod %s to be deleted: %v", podName, err)
 	}
 
-	newBuild = event.Object.(*buildv1.Build)
-	if newBuild.Status.Phase != buildv1.BuildPhasePending {
-		t.Fatalf("expected build status to be marked pending, but was marked %s", newBuild.Status.Phase)
+	foundCancelled := false
+	err = wait.Poll(time.Second, 30*time.Second, func() (bool, error) {
+		events, err := kClient.CoreV1().Events(ns).Search(legacyscheme.Scheme, cancelled)
+		if err != nil {
+			return false, fmt.Errorf("error getting build events: %v", err)
+		}
+		for _, event := range events.Items {
+			if event.Reason == buildutil.BuildCancelledEventReason {
+				foundCancelled = true
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected: %v", err)
+	}
+	if !foundCancelled {
+		t.Fatalf("expected to find a %s event on the build %s/%s", buildutil.BuildCancelledEventReason, cancelled.Namespace, cancelled.Name)
 	}
+}
 
-	newBuild.Status.Phase = buildv1.BuildPhaseComplete
-	clusterAdminClient.Builds(ns).Update(newBuild)
-	event = waitForWatch(t, "build updated to complete", buildWatch)
-	if e, a := watchapi.Modified, event.Type; e != a {
-		t.Fatalf("expected watch event type %s, got %s", e, a)
+func RunBuildCompletePodDeleteTest(t testingT, clusterAdminClient buildv1clienttyped.BuildsGetter, clusterAdminKubeClientset kubernetes.Interface, ns string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker := NewBuildLifecycleTracker(clusterAdminClient, clusterAdminKubeClientset, ns)
+	tracker.Start(ctx.Done())
+
+	newBuild, err := clusterAdminClient.Builds(ns).Create(mockBuild())
+	if err != nil {
+		t.Fatalf("Couldn't create Build: %v", err)
 	}
-	newBuild = event.Object.(*buildv1.Build)
-	if newBuild.Status.Phase != buildv1.BuildPhaseComplete {
-		t.Fatalf("expected build status to be marked complete, but was marked %s", newBuild.Status.Phase)
+
+	if err := tracker.WaitForPhase(newBuild.Name, buildv1.BuildPhasePending, BuildControllersWatchTimeout); err != nil {
+		t.Fatalf("waiting for build to be marked pending: %v", err)
 	}
 
-	clusterAdminKubeClientset.CoreV1().Pods(ns).Delete(buildutil.GetBuildPodName(newBuild), metav1.NewDeleteOptions(0))
-	time.Sleep(10 * time.Second)
 	newBuild, err = clusterAdminClient.Builds(ns).Get(newBuild.Name, metav1.GetOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
-	if newBuild.Status.Phase != buildv1.BuildPhaseComplete {
-		t.Fatalf("build status was updated to %s after deleting pod, should have stayed as %s", newBuild.Status.Phase, buildv1.BuildPhaseComplete)
+	newBuild.Status.Phase = buildv1.BuildPhaseComplete
+	if _, err := clusterAdminClient.Builds(ns).Update(newBuild); err != nil {
+		t.Fatalf("unexpected error marking build complete: %v", err)
+	}
+	if err := tracker.WaitForPhase(newBuild.Name, buildv1.BuildPhaseComplete, BuildControllersWatchTimeout); err != nil {
+		t.Fatalf("waiting for build to be marked complete: %v", err)
 	}
+
+	selector := BuildFieldSelector("", string(buildv1.BuildPhaseComplete), buildPodName(newBuild.Name))
+	selected, err := clusterAdminClient.Builds(ns).List(metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		t.Fatalf("listing builds by field selector %q: %v", selector, err)
+	}
+	found := false
+	for _, b := range selected.Items {
+		if b.Name == newBuild.Name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected field selector %q to find build %s", selector, newBuild.Name)
+	}
+
+	clusterAdminKubeClientset.CoreV1().Pods(ns).Delete(buildPodName(newBuild.Name), metav1.NewDeleteOptions(0))
+	tracker.AssertNoTransitionAfter(t, newBuild.Name, buildv1.BuildPhaseComplete, 10*time.Second)
 }
 
 func RunBuildConfigChangeControllerTest(t testingT, clusterAdminBuildClient buildv1clienttyped.BuildV1Interface, ns string) {
@@ -0,0 +1,39 @@
+package build
+
+import (
+	"k8s.io/apimachinery/pkg/fields"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+// SelectableFields mirrors the apiserver registry Strategy.GetAttrs
+// contract (see e.g. k8s.io/kubernetes pkg/registry/core/pod/strategy.go's
+// SelectableFields), exposing "status" and "podName" as field selectors
+// alongside the standard "metadata.name". This tree doesn't carry the
+// Build REST storage strategy that would call this as GetAttrs, so it's
+// kept here as the helper a future strategy.go can wire in directly.
+func SelectableFields(build *buildv1.Build) fields.Set {
+	return fields.Set{
+		"metadata.name": build.Name,
+		"status":        string(build.Status.Phase),
+		"podName":       buildPodName(build.Name),
+	}
+}
+
+// BuildFieldSelector builds a fields.Selector over SelectableFields for use
+// as metav1.ListOptions.FieldSelector on Builds().List/Watch, e.g. to watch
+// only the Complete builds for a given pod rather than every build in the
+// namespace. Empty arguments are omitted from the selector.
+func BuildFieldSelector(name, status, podName string) fields.Selector {
+	set := fields.Set{}
+	if name != "" {
+		set["metadata.name"] = name
+	}
+	if status != "" {
+		set["status"] = status
+	}
+	if podName != "" {
+		set["podName"] = podName
+	}
+	return set.AsSelector()
+}
@@ -0,0 +1,85 @@
+// Package ginkgo exposes the build controller lifecycle assertions in
+// test/common/build as Ginkgo Describe/It blocks, so extended e2e suites
+// can compose them declaratively (ginkgo.It("...", func() { ... }),
+// gomega.Eventually(BuildPhase(...)).Should(...)) instead of hand-rolling
+// waitForWatch calls. The testingT-based Run*Test functions remain the
+// canonical implementation; this package adapts Ginkgo's world onto the
+// testingT interface they already accept.
+package ginkgo
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/onsi/ginkgo"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	commonbuild "github.com/openshift/origin/test/common/build"
+)
+
+// ginkgoT adapts ginkgo.Fail/ginkgo.Skip onto the testingT interface the
+// test/common/build Run*Test helpers expect.
+type ginkgoT struct {
+	mu     sync.Mutex
+	failed bool
+}
+
+func (t *ginkgoT) markFailed() {
+	t.mu.Lock()
+	t.failed = true
+	t.mu.Unlock()
+}
+
+func (t *ginkgoT) Fail()                                     { t.markFailed(); ginkgo.Fail("") }
+func (t *ginkgoT) Error(args ...interface{})                 { t.markFailed(); ginkgo.Fail(fmt.Sprint(args...)) }
+func (t *ginkgoT) Errorf(format string, args ...interface{}) { t.markFailed(); ginkgo.Fail(fmt.Sprintf(format, args...)) }
+func (t *ginkgoT) FailNow()                                  { t.markFailed(); ginkgo.Fail("") }
+func (t *ginkgoT) Fatal(args ...interface{})                 { t.markFailed(); ginkgo.Fail(fmt.Sprint(args...)) }
+func (t *ginkgoT) Fatalf(format string, args ...interface{}) { t.markFailed(); ginkgo.Fail(fmt.Sprintf(format, args...)) }
+func (t *ginkgoT) Log(args ...interface{})                   { fmt.Fprintln(ginkgo.GinkgoWriter, args...) }
+func (t *ginkgoT) Logf(format string, args ...interface{})   { fmt.Fprintf(ginkgo.GinkgoWriter, format+"\n", args...) }
+func (t *ginkgoT) Parallel()                                 {}
+func (t *ginkgoT) Skip(args ...interface{})                  { ginkgo.Skip(fmt.Sprint(args...)) }
+func (t *ginkgoT) Skipf(format string, args ...interface{})  { ginkgo.Skip(fmt.Sprintf(format, args...)) }
+func (t *ginkgoT) SkipNow()                                  { ginkgo.Skip("") }
+func (t *ginkgoT) Skipped() bool                             { return false }
+
+func (t *ginkgoT) Failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+// BuildPhase returns a poll function suitable for gomega.Eventually or
+// gomega.Consistently, e.g.
+//
+//	Eventually(BuildPhase(buildClient, ns, build.Name)).Should(Equal(buildv1.BuildPhaseComplete))
+func BuildPhase(buildClient buildv1clienttyped.BuildsGetter, ns, name string) func() buildv1.BuildPhase {
+	return func() buildv1.BuildPhase {
+		b, err := buildClient.Builds(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		return b.Status.Phase
+	}
+}
+
+// DescribeBuildLifecycle registers Ginkgo It blocks covering the build
+// lifecycle assertions exercised by RunBuildCompletePodDeleteTest and
+// RunBuildConfigChangeControllerTest. ns is called fresh for each It so
+// the caller's BeforeEach can hand out a new namespace per spec.
+func DescribeBuildLifecycle(buildClient buildv1clienttyped.BuildV1Interface, kubeClient kubernetes.Interface, ns func() string) {
+	ginkgo.Describe("Build lifecycle", func() {
+		ginkgo.It("does not revert a Complete build's status when its pod is deleted", func() {
+			commonbuild.RunBuildCompletePodDeleteTest(&ginkgoT{}, buildClient, kubeClient, ns())
+		})
+
+		ginkgo.It("bumps BuildConfig.Status.LastVersion on a config-change triggered build", func() {
+			commonbuild.RunBuildConfigChangeControllerTest(&ginkgoT{}, buildClient, ns())
+		})
+	})
+}
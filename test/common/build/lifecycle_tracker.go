@@ -0,0 +1,211 @@
+package build
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	watchapi "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+)
+
+// buildRecord is the coalesced state BuildLifecycleTracker keeps for one
+// tracked name (a build or its pod): the build's current phase and when
+// it last changed, and the set of pod watch event types observed.
+type buildRecord struct {
+	phase        buildv1.BuildPhase
+	transitionAt time.Time
+	podEvents    map[watchapi.EventType]time.Time
+}
+
+// BuildLifecycleTracker subscribes once to Builds().Watch and Pods().Watch
+// for a namespace and coalesces their events into a per-build state
+// machine (Pending -> Running -> Complete/Failed/Cancelled/Error), so
+// tests can assert on build lifecycle state without hand-rolling
+// waitForWatch loops that are fragile to events arriving out of order or
+// the watch reconnecting.
+type BuildLifecycleTracker struct {
+	buildClient buildv1clienttyped.BuildsGetter
+	kubeClient  kubernetes.Interface
+	ns          string
+
+	mu      sync.Mutex
+	records map[string]*buildRecord
+}
+
+// NewBuildLifecycleTracker creates a tracker for namespace ns. Call Start
+// before waiting on any build or pod.
+func NewBuildLifecycleTracker(buildClient buildv1clienttyped.BuildsGetter, kubeClient kubernetes.Interface, ns string) *BuildLifecycleTracker {
+	return &BuildLifecycleTracker{
+		buildClient: buildClient,
+		kubeClient:  kubeClient,
+		ns:          ns,
+		records:     make(map[string]*buildRecord),
+	}
+}
+
+// Start runs the build and pod watch loops until stopCh is closed.
+func (tr *BuildLifecycleTracker) Start(stopCh <-chan struct{}) {
+	go tr.watchBuilds(stopCh)
+	go tr.watchPods(stopCh)
+}
+
+// watchBuilds runs a Builds().Watch loop, re-establishing the watch from
+// the last observed ResourceVersion whenever the result channel closes
+// (e.g. on a server-side watch timeout), so a reconnect doesn't lose
+// transitions that happen in between.
+func (tr *BuildLifecycleTracker) watchBuilds(stopCh <-chan struct{}) {
+	rv := ""
+	for {
+		w, err := tr.buildClient.Builds(tr.ns).Watch(metav1.ListOptions{ResourceVersion: rv})
+		if err != nil {
+			select {
+			case <-time.After(time.Second):
+				continue
+			case <-stopCh:
+				return
+			}
+		}
+	eventLoop:
+		for {
+			select {
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					break eventLoop
+				}
+				b, ok := event.Object.(*buildv1.Build)
+				if !ok {
+					continue
+				}
+				rv = b.ResourceVersion
+				tr.recordBuildPhase(b.Name, b.Status.Phase)
+			case <-stopCh:
+				w.Stop()
+				return
+			}
+		}
+		w.Stop()
+	}
+}
+
+// watchPods runs a Pods().Watch loop with the same reconnect-from-last-
+// ResourceVersion behavior as watchBuilds.
+func (tr *BuildLifecycleTracker) watchPods(stopCh <-chan struct{}) {
+	rv := ""
+	for {
+		w, err := tr.kubeClient.CoreV1().Pods(tr.ns).Watch(metav1.ListOptions{ResourceVersion: rv})
+		if err != nil {
+			select {
+			case <-time.After(time.Second):
+				continue
+			case <-stopCh:
+				return
+			}
+		}
+	eventLoop:
+		for {
+			select {
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					break eventLoop
+				}
+				pod, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+				rv = pod.ResourceVersion
+				tr.recordPodEvent(pod.Name, event.Type)
+			case <-stopCh:
+				w.Stop()
+				return
+			}
+		}
+		w.Stop()
+	}
+}
+
+func (tr *BuildLifecycleTracker) recordFor(key string) *buildRecord {
+	rec, ok := tr.records[key]
+	if !ok {
+		rec = &buildRecord{podEvents: make(map[watchapi.EventType]time.Time)}
+		tr.records[key] = rec
+	}
+	return rec
+}
+
+func (tr *BuildLifecycleTracker) recordBuildPhase(name string, phase buildv1.BuildPhase) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	rec := tr.recordFor(name)
+	if rec.phase != phase {
+		rec.phase = phase
+		rec.transitionAt = time.Now()
+	}
+}
+
+func (tr *BuildLifecycleTracker) recordPodEvent(podName string, eventType watchapi.EventType) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.recordFor(podName).podEvents[eventType] = time.Now()
+}
+
+// buildPodName is the deterministic name the build controller gives a
+// build's pod.
+func buildPodName(buildName string) string {
+	return buildName + "-build"
+}
+
+// WaitForPhase blocks until the named build's phase equals phase, or
+// returns an error once timeout elapses.
+func (tr *BuildLifecycleTracker) WaitForPhase(name string, phase buildv1.BuildPhase, timeout time.Duration) error {
+	return wait.PollImmediate(100*time.Millisecond, timeout, func() (bool, error) {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		rec, ok := tr.records[name]
+		return ok && rec.phase == phase, nil
+	})
+}
+
+// WaitForPodEvent blocks until a watch event of eventType has been
+// observed for the named build's pod, or returns an error once timeout
+// elapses.
+func (tr *BuildLifecycleTracker) WaitForPodEvent(name string, eventType watchapi.EventType, timeout time.Duration) error {
+	podName := buildPodName(name)
+	return wait.PollImmediate(100*time.Millisecond, timeout, func() (bool, error) {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		rec, ok := tr.records[podName]
+		if !ok {
+			return false, nil
+		}
+		_, seen := rec.podEvents[eventType]
+		return seen, nil
+	})
+}
+
+// AssertNoTransitionAfter checks the named build's recorded phase once a
+// second until duration elapses and fails test if it is anything other than
+// phase at one of those checks. A change that is reverted between two checks
+// is not detected.
+func (tr *BuildLifecycleTracker) AssertNoTransitionAfter(test testingT, name string, phase buildv1.BuildPhase, duration time.Duration) {
+	deadline := time.After(duration)
+	for {
+		select {
+		case <-deadline:
+			return
+		case <-time.After(time.Second):
+			tr.mu.Lock()
+			rec, ok := tr.records[name]
+			tr.mu.Unlock()
+			if ok && rec.phase != phase {
+				test.Fatalf("build %s unexpectedly transitioned to %s while expecting to stay %s", name, rec.phase, phase)
+				return
+			}
+		}
+	}
+}
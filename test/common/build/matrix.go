@@ -0,0 +1,115 @@
+package build
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+)
+
+// PodDeletionBehavior is the build-pod-deletion invariant RunBuildMatrix
+// checks for a row, beyond just reaching ExpectedFinalPhase.
+type PodDeletionBehavior int
+
+const (
+	// PodDeletionNone means the row doesn't exercise pod deletion.
+	PodDeletionNone PodDeletionBehavior = iota
+	// PodDeletionErrors means deleting the build's pod while it is still
+	// Pending/Running must move the build to Error.
+	PodDeletionErrors
+)
+
+// BuildMatrixRow is one strategy/trigger combination RunBuildMatrix
+// exercises end to end. Adding coverage for a new strategy or trigger
+// combination is a new row rather than a copy-pasted Run*Test.
+type BuildMatrixRow struct {
+	// Name identifies the row in test failure messages.
+	Name string
+	// Options configure the strategy (and, where relevant, the source)
+	// of the build created for this row; nil means the default
+	// DockerStrategy/Git mockBuild.
+	Options []MockBuildOption
+	// TriggerType documents which BuildConfig trigger this row
+	// approximates. RunBuildMatrix creates builds directly rather than
+	// through a BuildConfig and its trigger machinery, so this is
+	// recorded for context rather than exercised.
+	TriggerType buildv1.BuildTriggerType
+	// ExpectedFinalPhase is the terminal phase the build must reach.
+	ExpectedFinalPhase buildv1.BuildPhase
+	// PodDeletionBehavior is the pod-deletion invariant to check, if any.
+	PodDeletionBehavior PodDeletionBehavior
+}
+
+// StandardRows are the strategy/trigger combinations hard-coded across
+// the controller tests in this package (see RunBuildControllerTest's
+// StandardStrategyCases, RunImageChangeTriggerTest and
+// RunBuildConfigChangeControllerTest), collected into one table.
+func StandardRows() []BuildMatrixRow {
+	return []BuildMatrixRow{
+		{
+			Name:               "docker strategy, config change trigger",
+			TriggerType:        buildv1.ConfigChangeBuildTriggerType,
+			ExpectedFinalPhase: buildv1.BuildPhaseComplete,
+		},
+		{
+			Name: "source strategy, image change trigger",
+			Options: []MockBuildOption{
+				WithSourceStrategy(&corev1.ObjectReference{Kind: "DockerImage", Name: "openshift/sti-image"}),
+			},
+			TriggerType:         buildv1.ImageChangeBuildTriggerType,
+			ExpectedFinalPhase:  buildv1.BuildPhaseError,
+			PodDeletionBehavior: PodDeletionErrors,
+		},
+		{
+			Name: "custom strategy, config change trigger",
+			Options: []MockBuildOption{
+				WithCustomStrategy("openshift/custom-builder", nil),
+			},
+			TriggerType:         buildv1.ConfigChangeBuildTriggerType,
+			ExpectedFinalPhase:  buildv1.BuildPhaseError,
+			PodDeletionBehavior: PodDeletionErrors,
+		},
+	}
+}
+
+// RunBuildMatrix creates and runs the build described by each row, in
+// order, reporting each row's failures against its own name so a failure
+// in one row doesn't obscure the rest.
+func RunBuildMatrix(t testingT, buildClient buildv1clienttyped.BuildV1Interface, kubeClient kubernetes.Interface, ns string, rows []BuildMatrixRow) {
+	for _, row := range rows {
+		runBuildMatrixRow(t, buildClient, kubeClient, ns, row)
+	}
+}
+
+func runBuildMatrixRow(t testingT, buildClient buildv1clienttyped.BuildV1Interface, kubeClient kubernetes.Interface, ns string, row BuildMatrixRow) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker := NewBuildLifecycleTracker(buildClient, kubeClient, ns)
+	tracker.Start(ctx.Done())
+
+	b, err := buildClient.Builds(ns).Create(mockBuild(row.Options...))
+	if err != nil {
+		t.Errorf("%s: creating build: %v", row.Name, err)
+		return
+	}
+
+	if row.PodDeletionBehavior == PodDeletionErrors {
+		if err := tracker.WaitForPhase(b.Name, buildv1.BuildPhasePending, BuildControllersWatchTimeout); err != nil {
+			t.Errorf("%s: waiting for build to be marked pending: %v", row.Name, err)
+			return
+		}
+		if err := kubeClient.CoreV1().Pods(ns).Delete(buildPodName(b.Name), metav1.NewDeleteOptions(0)); err != nil {
+			t.Errorf("%s: deleting build pod: %v", row.Name, err)
+			return
+		}
+	}
+
+	if err := tracker.WaitForPhase(b.Name, row.ExpectedFinalPhase, BuildControllersWatchTimeout); err != nil {
+		t.Errorf("%s: %v", row.Name, err)
+	}
+}
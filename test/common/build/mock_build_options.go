@@ -0,0 +1,160 @@
+package build
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildutil "github.com/openshift/openshift-controller-manager/pkg/build/buildutil"
+)
+
+// MockBuildOption customizes a Build produced by mockBuild. Each option
+// replaces the strategy and/or source the build was seeded with, so tests
+// can exercise the controller against every strategy it supports instead
+// of only DockerStrategy.
+type MockBuildOption func(*buildv1.Build)
+
+// WithSourceStrategy switches the mock build to SourceStrategy (an STI
+// build), pulling from the given image reference.
+func WithSourceStrategy(from *corev1.ObjectReference) MockBuildOption {
+	return func(b *buildv1.Build) {
+		b.Spec.Strategy = buildv1.BuildStrategy{
+			SourceStrategy: &buildv1.SourceBuildStrategy{
+				From: *from,
+			},
+		}
+	}
+}
+
+// WithCustomStrategy switches the mock build to CustomStrategy, building
+// with the given builder image and environment.
+func WithCustomStrategy(image string, env []corev1.EnvVar) MockBuildOption {
+	return func(b *buildv1.Build) {
+		b.Spec.Strategy = buildv1.BuildStrategy{
+			CustomStrategy: &buildv1.CustomBuildStrategy{
+				From: corev1.ObjectReference{
+					Kind: "DockerImage",
+					Name: image,
+				},
+				Env: env,
+			},
+		}
+	}
+}
+
+// WithJenkinsPipelineStrategy switches the mock build to
+// JenkinsPipelineStrategy, using the given inline Jenkinsfile.
+func WithJenkinsPipelineStrategy(jenkinsfile string) MockBuildOption {
+	return func(b *buildv1.Build) {
+		b.Spec.Strategy = buildv1.BuildStrategy{
+			JenkinsPipelineStrategy: &buildv1.JenkinsPipelineBuildStrategy{
+				Jenkinsfile: jenkinsfile,
+			},
+		}
+	}
+}
+
+// WithBinarySource replaces the mock build's Git source with an empty
+// Binary source, as used by `oc start-build --from-dir`.
+func WithBinarySource() MockBuildOption {
+	return func(b *buildv1.Build) {
+		b.Spec.Source.Git = nil
+		b.Spec.Source.ContextDir = ""
+		b.Spec.Source.Binary = &buildv1.BinaryBuildSource{}
+	}
+}
+
+// WithOutputPushSecret sets the push secret referenced by the mock build's
+// output.
+func WithOutputPushSecret(name string) MockBuildOption {
+	return func(b *buildv1.Build) {
+		b.Spec.Output.PushSecret = &corev1.LocalObjectReference{Name: name}
+	}
+}
+
+// WithRunPolicy points the mock build at a BuildConfig and run policy,
+// mirroring the labels the build controller expects on a build created
+// for that BuildConfig.
+func WithRunPolicy(bcName string, policy buildv1.BuildRunPolicy) MockBuildOption {
+	return func(b *buildv1.Build) {
+		b.Labels[buildv1.BuildConfigLabel] = bcName
+		b.Labels[buildv1.BuildRunPolicyLabel] = string(policy)
+	}
+}
+
+// buildStrategyCase is one row of the strategy table looped over by
+// RunBuildControllerTest and RunBuildControllerPodSyncTest so that both
+// cover more than the DockerStrategy code path.
+type buildStrategyCase struct {
+	// Name identifies the case in test failure messages.
+	Name string
+	// Options configures the strategy (and, where relevant, the source)
+	// of the mock build used by this case.
+	Options []MockBuildOption
+	// ExpectedImage is the builder image the controller should resolve
+	// into the build pod's first container, empty if not asserted.
+	ExpectedImage string
+	// ExpectedEnv are environment variables that must be present on the
+	// build pod's first container.
+	ExpectedEnv []corev1.EnvVar
+	// Privileged is whether the build pod's first container is expected
+	// to run privileged.
+	Privileged bool
+	// ExpectInvalid marks a case that should fail setup rather than
+	// produce a pod, in which case ExpectedFailReason must be observed as
+	// an event on the build.
+	ExpectInvalid      bool
+	ExpectedFailReason string
+}
+
+// StandardStrategyCases returns the strategy/source combinations exercised
+// by the build controller tests in this package.
+func StandardStrategyCases() []buildStrategyCase {
+	return []buildStrategyCase{
+		{
+			Name:          "docker strategy",
+			ExpectedImage: "openshift/origin-docker-builder",
+		},
+		{
+			Name: "source strategy",
+			Options: []MockBuildOption{
+				WithSourceStrategy(&corev1.ObjectReference{Kind: "DockerImage", Name: "openshift/sti-image"}),
+			},
+			ExpectedImage: "openshift/sti-image",
+		},
+		{
+			Name: "custom strategy",
+			Options: []MockBuildOption{
+				WithCustomStrategy("openshift/custom-builder", []corev1.EnvVar{
+					{Name: "CUSTOM_VAR", Value: "custom-value"},
+				}),
+			},
+			ExpectedImage: "openshift/custom-builder",
+			ExpectedEnv:   []corev1.EnvVar{{Name: "CUSTOM_VAR", Value: "custom-value"}},
+			Privileged:    true,
+		},
+		{
+			Name: "jenkins pipeline strategy",
+			Options: []MockBuildOption{
+				WithJenkinsPipelineStrategy("node { echo 'hello' }"),
+			},
+		},
+		{
+			Name: "binary source, docker strategy",
+			Options: []MockBuildOption{
+				WithBinarySource(),
+			},
+			ExpectedImage: "openshift/origin-docker-builder",
+		},
+		{
+			Name: "custom strategy with invalid output",
+			Options: []MockBuildOption{
+				WithCustomStrategy("openshift/custom-builder", nil),
+				func(b *buildv1.Build) {
+					b.Spec.Output.To.Name = ""
+				},
+			},
+			ExpectInvalid:      true,
+			ExpectedFailReason: buildutil.BuildFailedEventReason,
+		},
+	}
+}
@@ -0,0 +1,178 @@
+package build
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+)
+
+// PodDeletionMode selects the DeleteOptions RunBuildPodDeletionTest issues
+// against a build's pod, mirroring the NewDeleteOptions/PropagationPolicy
+// surface client-go exposes.
+type PodDeletionMode int
+
+const (
+	// PodDeletionForeground deletes with PropagationPolicy=Foreground.
+	PodDeletionForeground PodDeletionMode = iota
+	// PodDeletionBackground deletes with PropagationPolicy=Background.
+	PodDeletionBackground
+	// PodDeletionOrphan deletes with PropagationPolicy=Orphan.
+	PodDeletionOrphan
+	// PodDeletionGracePeriodN deletes with an explicit, non-zero grace
+	// period instead of immediate (GracePeriodSeconds: 0) deletion.
+	PodDeletionGracePeriodN
+)
+
+func (m PodDeletionMode) deleteOptions() *metav1.DeleteOptions {
+	switch m {
+	case PodDeletionForeground:
+		policy := metav1.DeletePropagationForeground
+		return &metav1.DeleteOptions{PropagationPolicy: &policy}
+	case PodDeletionBackground:
+		policy := metav1.DeletePropagationBackground
+		return &metav1.DeleteOptions{PropagationPolicy: &policy}
+	case PodDeletionOrphan:
+		policy := metav1.DeletePropagationOrphan
+		return &metav1.DeleteOptions{PropagationPolicy: &policy}
+	case PodDeletionGracePeriodN:
+		grace := int64(30)
+		return &metav1.DeleteOptions{GracePeriodSeconds: &grace}
+	default:
+		return metav1.NewDeleteOptions(0)
+	}
+}
+
+func (m PodDeletionMode) String() string {
+	switch m {
+	case PodDeletionForeground:
+		return "Foreground"
+	case PodDeletionBackground:
+		return "Background"
+	case PodDeletionOrphan:
+		return "Orphan"
+	case PodDeletionGracePeriodN:
+		return "GracePeriodN"
+	default:
+		return "Unknown"
+	}
+}
+
+// RunBuildPodDeletionTest asserts the build controller's reaction to a
+// build pod being deleted under mode is the same regardless of which
+// DeleteOptions triggered it: a Complete build's phase must never
+// regress, a Pending or Running build must move to Error, and for PodDeletionOrphan
+// the pod's owner references must be unchanged by the delete call itself.
+// timeout bounds how long each assertion polls for its expected outcome.
+func RunBuildPodDeletionTest(t testingT, buildClient buildv1clienttyped.BuildsGetter, kubeClient kubernetes.Interface, ns string, mode PodDeletionMode, timeout time.Duration) {
+	assertCompleteBuildPodDeletion(t, buildClient, kubeClient, ns, mode, timeout)
+	assertRunningBuildPodDeletion(t, buildClient, kubeClient, ns, mode, timeout)
+}
+
+// assertCompleteBuildPodDeletion verifies that deleting a Complete
+// build's pod under mode never regresses the build's phase.
+func assertCompleteBuildPodDeletion(t testingT, buildClient buildv1clienttyped.BuildsGetter, kubeClient kubernetes.Interface, ns string, mode PodDeletionMode, timeout time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker := NewBuildLifecycleTracker(buildClient, kubeClient, ns)
+	tracker.Start(ctx.Done())
+
+	b, err := buildClient.Builds(ns).Create(mockBuild())
+	if err != nil {
+		t.Errorf("%s/complete: creating build: %v", mode, err)
+		return
+	}
+
+	if err := tracker.WaitForPhase(b.Name, buildv1.BuildPhasePending, timeout); err != nil {
+		t.Errorf("%s/complete: waiting for build to be marked pending: %v", mode, err)
+		return
+	}
+
+	b, err = buildClient.Builds(ns).Get(b.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("%s/complete: getting build: %v", mode, err)
+		return
+	}
+	b.Status.Phase = buildv1.BuildPhaseComplete
+	if _, err := buildClient.Builds(ns).Update(b); err != nil {
+		t.Errorf("%s/complete: marking build complete: %v", mode, err)
+		return
+	}
+	if err := tracker.WaitForPhase(b.Name, buildv1.BuildPhaseComplete, timeout); err != nil {
+		t.Errorf("%s/complete: waiting for build to be marked complete: %v", mode, err)
+		return
+	}
+
+	if err := kubeClient.CoreV1().Pods(ns).Delete(buildPodName(b.Name), mode.deleteOptions()); err != nil {
+		t.Errorf("%s/complete: deleting build pod: %v", mode, err)
+		return
+	}
+	tracker.AssertNoTransitionAfter(t, b.Name, buildv1.BuildPhaseComplete, timeout)
+}
+
+// assertRunningBuildPodDeletion verifies that deleting a Pending/Running
+// build's pod under mode moves the build to Error, and for
+// PodDeletionOrphan that the pod's owner references were left untouched
+// by the delete call.
+func assertRunningBuildPodDeletion(t testingT, buildClient buildv1clienttyped.BuildsGetter, kubeClient kubernetes.Interface, ns string, mode PodDeletionMode, timeout time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker := NewBuildLifecycleTracker(buildClient, kubeClient, ns)
+	tracker.Start(ctx.Done())
+
+	b, err := buildClient.Builds(ns).Create(mockBuild())
+	if err != nil {
+		t.Errorf("%s/running: creating build: %v", mode, err)
+		return
+	}
+
+	if err := tracker.WaitForPhase(b.Name, buildv1.BuildPhasePending, timeout); err != nil {
+		t.Errorf("%s/running: waiting for build to be marked pending: %v", mode, err)
+		return
+	}
+
+	podName := buildPodName(b.Name)
+	var ownerRefsBefore []metav1.OwnerReference
+	if mode == PodDeletionOrphan {
+		pod, err := kubeClient.CoreV1().Pods(ns).Get(podName, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("%s/running: getting build pod: %v", mode, err)
+			return
+		}
+		ownerRefsBefore = pod.OwnerReferences
+	}
+
+	if err := kubeClient.CoreV1().Pods(ns).Delete(podName, mode.deleteOptions()); err != nil {
+		t.Errorf("%s/running: deleting build pod: %v", mode, err)
+		return
+	}
+
+	if mode == PodDeletionOrphan {
+		pod, err := kubeClient.CoreV1().Pods(ns).Get(podName, metav1.GetOptions{})
+		if err == nil && !ownerReferencesEqual(ownerRefsBefore, pod.OwnerReferences) {
+			t.Errorf("%s/running: pod owner references changed across an orphan-propagation delete: had %v, now %v", mode, ownerRefsBefore, pod.OwnerReferences)
+		}
+	}
+
+	if err := tracker.WaitForPhase(b.Name, buildv1.BuildPhaseError, timeout); err != nil {
+		t.Errorf("%s/running: waiting for build to be marked error after pod loss: %v", mode, err)
+	}
+}
+
+func ownerReferencesEqual(a, b []metav1.OwnerReference) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].UID != b[i].UID {
+			return false
+		}
+	}
+	return true
+}
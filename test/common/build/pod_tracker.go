@@ -0,0 +1,191 @@
+package build
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	watchapi "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+)
+
+// BuildPhase is the build lifecycle phase a BuildPodTracker dispatches
+// OnBuildPhase callbacks with.
+type BuildPhase = buildv1.BuildPhase
+
+// BuildPodTracker runs shared informers scoped to a single build's Pod and
+// Build object, dispatching typed callbacks to tests in place of the
+// ad-hoc watch goroutines + channels each Run*Test function used to hand
+// roll. PodSynced/BuildSynced are cache.InformerSynced funcs suitable for
+// cache.WaitForCacheSync.
+type BuildPodTracker struct {
+	podInformer   cache.SharedIndexInformer
+	buildInformer cache.SharedIndexInformer
+
+	mu           sync.Mutex
+	onBuildPhase []func(buildv1.BuildPhase)
+	onPodPhase   []func(corev1.PodPhase)
+	onPodEvent   []func(reason, message string)
+}
+
+// NewBuildPodTracker creates a BuildPodTracker scoped to the pod named
+// podName and the build named buildName in namespace ns. Call Start before
+// waiting on PodSynced/BuildSynced.
+func NewBuildPodTracker(kClient kubernetes.Interface, buildClient buildv1clienttyped.BuildsGetter, ns, buildName, podName string) *BuildPodTracker {
+	tracker := &BuildPodTracker{}
+
+	podListWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", podName).String()
+			return kClient.CoreV1().Pods(ns).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watchapi.Interface, error) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", podName).String()
+			return kClient.CoreV1().Pods(ns).Watch(options)
+		},
+	}
+	tracker.podInformer = cache.NewSharedIndexInformer(podListWatch, &corev1.Pod{}, 0, cache.Indexers{})
+	tracker.podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    tracker.handlePod,
+		UpdateFunc: func(_, obj interface{}) { tracker.handlePod(obj) },
+	})
+
+	buildListWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", buildName).String()
+			return buildClient.Builds(ns).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watchapi.Interface, error) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", buildName).String()
+			return buildClient.Builds(ns).Watch(options)
+		},
+	}
+	tracker.buildInformer = cache.NewSharedIndexInformer(buildListWatch, &buildv1.Build{}, 0, cache.Indexers{})
+	tracker.buildInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    tracker.handleBuild,
+		UpdateFunc: func(_, obj interface{}) { tracker.handleBuild(obj) },
+	})
+
+	return tracker
+}
+
+// Start runs the pod and build informers until stopCh is closed.
+func (t *BuildPodTracker) Start(stopCh <-chan struct{}) {
+	go t.podInformer.Run(stopCh)
+	go t.buildInformer.Run(stopCh)
+}
+
+// PodSynced reports whether the pod informer's initial list has landed.
+func (t *BuildPodTracker) PodSynced() bool {
+	return t.podInformer.HasSynced()
+}
+
+// BuildSynced reports whether the build informer's initial list has landed.
+func (t *BuildPodTracker) BuildSynced() bool {
+	return t.buildInformer.HasSynced()
+}
+
+// OnBuildPhase registers a callback invoked with the tracked build's phase
+// every time it is observed, including on the initial sync. Callbacks run
+// on the informer's goroutine.
+func (t *BuildPodTracker) OnBuildPhase(fn func(phase buildv1.BuildPhase)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onBuildPhase = append(t.onBuildPhase, fn)
+}
+
+// OnPodPhase registers a callback invoked with the tracked pod's phase
+// every time it is observed, including on the initial sync. Callbacks run
+// on the informer's goroutine.
+func (t *BuildPodTracker) OnPodPhase(fn func(phase corev1.PodPhase)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onPodPhase = append(t.onPodPhase, fn)
+}
+
+// OnPodEvent registers a callback invoked for every pod status condition
+// that carries a reason (e.g. Unschedulable or ContainersNotReady) each time
+// the tracked pod is observed. Container status reasons are not reported.
+// Callbacks run on the informer's goroutine.
+func (t *BuildPodTracker) OnPodEvent(fn func(reason, message string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onPodEvent = append(t.onPodEvent, fn)
+}
+
+func (t *BuildPodTracker) handlePod(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	phaseHandlers := append([]func(corev1.PodPhase){}, t.onPodPhase...)
+	eventHandlers := append([]func(string, string){}, t.onPodEvent...)
+	t.mu.Unlock()
+
+	for _, fn := range phaseHandlers {
+		fn(pod.Status.Phase)
+	}
+	for _, cond := range pod.Status.Conditions {
+		if len(cond.Reason) == 0 {
+			continue
+		}
+		for _, fn := range eventHandlers {
+			fn(cond.Reason, cond.Message)
+		}
+	}
+}
+
+func (t *BuildPodTracker) handleBuild(obj interface{}) {
+	build, ok := obj.(*buildv1.Build)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	handlers := append([]func(buildv1.BuildPhase){}, t.onBuildPhase...)
+	t.mu.Unlock()
+
+	for _, fn := range handlers {
+		fn(build.Status.Phase)
+	}
+}
+
+// ExpectTransitions asserts that the tracked build passes through phases,
+// in order, within timeout, failing test if a different phase is observed
+// next or the sequence doesn't complete in time.
+func (t *BuildPodTracker) ExpectTransitions(test testingT, phases []BuildPhase, timeout time.Duration) {
+	seen := make(chan BuildPhase, len(phases)+1)
+	t.OnBuildPhase(func(phase buildv1.BuildPhase) {
+		seen <- phase
+	})
+
+	deadline := time.After(timeout)
+	next := 0
+	for next < len(phases) {
+		select {
+		case phase := <-seen:
+			switch {
+			case phase == phases[next]:
+				next++
+			case next > 0 && phase == phases[next-1]:
+				// A duplicate delivery of the phase we already accepted
+				// (e.g. a resync re-sending the same object); ignore it.
+			default:
+				test.Fatalf("expected build phase %s next, got %s", phases[next], phase)
+				return
+			}
+		case <-deadline:
+			test.Fatalf("timed out after %s waiting for build phase %s (%d/%d transitions seen)", timeout, phases[next], next, len(phases))
+			return
+		}
+	}
+}
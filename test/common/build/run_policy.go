@@ -0,0 +1,230 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	buildutil "github.com/openshift/openshift-controller-manager/pkg/build/buildutil"
+)
+
+// runPolicyCases are the BuildConfig run policies whose controller
+// serialization invariants RunBuildRunPolicyTest checks.
+var runPolicyCases = []buildv1.BuildRunPolicy{
+	buildv1.BuildRunPolicySerial,
+	buildv1.BuildRunPolicySerialLatestOnly,
+	buildv1.BuildRunPolicyParallel,
+}
+
+// buildPhaseTransition is one observed (phase, when) pair for a tracked
+// build, used to reconstruct how long a build spent active so overlaps
+// between builds can be detected after the fact.
+type buildPhaseTransition struct {
+	Phase buildv1.BuildPhase
+	At    time.Time
+}
+
+var activePhases = map[buildv1.BuildPhase]bool{
+	buildv1.BuildPhasePending: true,
+	buildv1.BuildPhaseRunning: true,
+}
+
+var terminalPhases = map[buildv1.BuildPhase]bool{
+	buildv1.BuildPhaseComplete:  true,
+	buildv1.BuildPhaseFailed:    true,
+	buildv1.BuildPhaseError:     true,
+	buildv1.BuildPhaseCancelled: true,
+}
+
+// RunBuildRunPolicyTest creates a BuildConfig and three Builds in rapid
+// succession under each of Serial, SerialLatestOnly and Parallel, and
+// asserts the controller serializes (or doesn't) their execution according
+// to that policy.
+func RunBuildRunPolicyTest(t testingT, buildClient buildv1clienttyped.BuildV1Interface, kClient kubernetes.Interface, ns string) {
+	for _, policy := range runPolicyCases {
+		runBuildRunPolicyTestCase(t, buildClient, kClient, ns, policy)
+	}
+}
+
+func runBuildRunPolicyTestCase(t testingT, buildClient buildv1clienttyped.BuildV1Interface, kClient kubernetes.Interface, ns string, policy buildv1.BuildRunPolicy) {
+	bcName := fmt.Sprintf("run-policy-%s", strings.ToLower(string(policy)))
+	if _, err := buildClient.BuildConfigs(ns).Create(runPolicyBuildConfig(ns, bcName, policy)); err != nil {
+		t.Fatalf("%s: creating build config: %v", policy, err)
+	}
+
+	builds := make([]*buildv1.Build, 0, 3)
+	for i := 0; i < 3; i++ {
+		b, err := buildClient.Builds(ns).Create(mockBuild(WithRunPolicy(bcName, policy)))
+		if err != nil {
+			t.Fatalf("%s: creating build %d: %v", policy, i, err)
+		}
+		builds = append(builds, b)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	log := make(map[string][]buildPhaseTransition, len(builds))
+	for _, b := range builds {
+		name := b.Name
+		tracker := NewBuildPodTracker(kClient, buildClient, ns, name, buildutil.GetBuildPodName(b))
+		tracker.OnBuildPhase(func(phase buildv1.BuildPhase) {
+			mu.Lock()
+			defer mu.Unlock()
+			transitions := log[name]
+			if len(transitions) > 0 && transitions[len(transitions)-1].Phase == phase {
+				// A duplicate delivery of the phase already recorded (e.g.
+				// a resync re-sending the same object); ignore it.
+				return
+			}
+			log[name] = append(transitions, buildPhaseTransition{Phase: phase, At: time.Now()})
+		})
+		tracker.Start(ctx.Done())
+		if !cache.WaitForCacheSync(ctx.Done(), tracker.PodSynced, tracker.BuildSynced) {
+			t.Fatalf("%s: timed out waiting for build pod tracker caches to sync for %s", policy, name)
+		}
+	}
+
+	if err := waitAllTerminal(&mu, log, builds, BuildControllersWatchTimeout); err != nil {
+		t.Fatalf("%s: %v", policy, err)
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	switch policy {
+	case buildv1.BuildRunPolicySerial:
+		assertNoOverlap(t, policy, builds, log)
+		assertCompletionOrder(t, policy, builds, log)
+	case buildv1.BuildRunPolicySerialLatestOnly:
+		assertNoOverlap(t, policy, builds, log)
+		assertLatestSurvives(t, policy, builds, log)
+	case buildv1.BuildRunPolicyParallel:
+		assertAllComplete(t, policy, builds, log)
+	}
+}
+
+// waitAllTerminal blocks until every build has recorded a terminal phase
+// transition, or timeout elapses.
+func waitAllTerminal(mu *sync.Mutex, log map[string][]buildPhaseTransition, builds []*buildv1.Build, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		mu.Lock()
+		done := true
+		for _, b := range builds {
+			transitions := log[b.Name]
+			if len(transitions) == 0 || !terminalPhases[transitions[len(transitions)-1].Phase] {
+				done = false
+				break
+			}
+		}
+		mu.Unlock()
+		if done {
+			return nil
+		}
+		select {
+		case <-deadline:
+			return fmt.Errorf("timed out waiting for all builds to reach a terminal phase")
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// activeWindow returns the [start, end) during which a build's phase was
+// Pending or Running, derived from its recorded transitions. The build is
+// assumed to have already reached a terminal phase.
+func activeWindow(transitions []buildPhaseTransition) (start, end time.Time, ok bool) {
+	for i, tr := range transitions {
+		if activePhases[tr.Phase] {
+			if !ok {
+				start = tr.At
+				ok = true
+			}
+			if i+1 < len(transitions) {
+				end = transitions[i+1].At
+			}
+		}
+	}
+	return start, end, ok
+}
+
+func assertNoOverlap(t testingT, policy buildv1.BuildRunPolicy, builds []*buildv1.Build, log map[string][]buildPhaseTransition) {
+	type window struct {
+		name       string
+		start, end time.Time
+	}
+	var windows []window
+	for _, b := range builds {
+		start, end, ok := activeWindow(log[b.Name])
+		if !ok {
+			continue
+		}
+		windows = append(windows, window{b.Name, start, end})
+	}
+	for i := 0; i < len(windows); i++ {
+		for j := i + 1; j < len(windows); j++ {
+			a, b := windows[i], windows[j]
+			if a.start.Before(b.end) && b.start.Before(a.end) {
+				t.Errorf("%s: builds %s and %s were both Pending/Running at the same time", policy, a.name, b.name)
+			}
+		}
+	}
+}
+
+func assertCompletionOrder(t testingT, policy buildv1.BuildRunPolicy, builds []*buildv1.Build, log map[string][]buildPhaseTransition) {
+	var last time.Time
+	for i, b := range builds {
+		transitions := log[b.Name]
+		if len(transitions) == 0 {
+			continue
+		}
+		finishedAt := transitions[len(transitions)-1].At
+		if i > 0 && finishedAt.Before(last) {
+			t.Errorf("%s: build %s (created %d) finished before an earlier build", policy, b.Name, i)
+		}
+		last = finishedAt
+	}
+}
+
+func assertLatestSurvives(t testingT, policy buildv1.BuildRunPolicy, builds []*buildv1.Build, log map[string][]buildPhaseTransition) {
+	last := builds[len(builds)-1]
+	transitions := log[last.Name]
+	if len(transitions) == 0 {
+		t.Errorf("%s: no phase transitions recorded for the latest build %s", policy, last.Name)
+		return
+	}
+	if finalPhase := transitions[len(transitions)-1].Phase; finalPhase == buildv1.BuildPhaseCancelled {
+		t.Errorf("%s: the latest build %s was cancelled; SerialLatestOnly should only cancel stale builds", policy, last.Name)
+	}
+}
+
+func assertAllComplete(t testingT, policy buildv1.BuildRunPolicy, builds []*buildv1.Build, log map[string][]buildPhaseTransition) {
+	for _, b := range builds {
+		transitions := log[b.Name]
+		if len(transitions) == 0 {
+			t.Errorf("%s: no phase transitions recorded for build %s", policy, b.Name)
+			continue
+		}
+		if finalPhase := transitions[len(transitions)-1].Phase; finalPhase != buildv1.BuildPhaseComplete {
+			t.Errorf("%s: expected build %s to complete, ended in %s", policy, b.Name, finalPhase)
+		}
+	}
+}
+
+func runPolicyBuildConfig(ns, name string, policy buildv1.BuildRunPolicy) *buildv1.BuildConfig {
+	bc := &buildv1.BuildConfig{}
+	bc.Name = name
+	bc.Namespace = ns
+	bc.Spec.RunPolicy = policy
+	bc.Spec.Source.Git = &buildv1.GitBuildSource{URI: "http://my.docker/build"}
+	bc.Spec.Strategy.DockerStrategy = &buildv1.DockerBuildStrategy{}
+	return bc
+}
@@ -0,0 +1,69 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1clienttyped "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+)
+
+// WaitForBuildPhases watches the named build until it has passed through
+// phases, in order, or ctx is done or timeout elapses, whichever comes
+// first. It returns the build as last observed in the final expected phase.
+//
+// Any phase observed that is neither the next expected phase nor a repeat
+// delivery of the one just accepted (a resync re-sending the same object,
+// say) is treated as an illegal transition — a Running build regressing to
+// New, for instance — and fails fast with an error identifying it, rather
+// than waiting out the full timeout.
+func WaitForBuildPhases(ctx context.Context, buildClient buildv1clienttyped.BuildInterface, name string, phases []buildv1.BuildPhase, timeout time.Duration) (*buildv1.Build, error) {
+	w, err := buildClient.Watch(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not watch build %q: %v", name, err)
+	}
+	defer w.Stop()
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.Stop()
+		case <-stopped:
+		}
+	}()
+
+	next := 0
+	var result *buildv1.Build
+	_, err = watch.Until(timeout, w, func(event watch.Event) (bool, error) {
+		build, ok := event.Object.(*buildv1.Build)
+		if !ok {
+			return false, nil
+		}
+		phase := build.Status.Phase
+		switch {
+		case phase == phases[next]:
+			result = build
+			next++
+			return next == len(phases), nil
+		case next > 0 && phase == phases[next-1]:
+			// A duplicate delivery of the phase we already accepted (e.g. a
+			// resync re-sending the same object); ignore it.
+			return false, nil
+		default:
+			return false, fmt.Errorf("build %q reached illegal phase %s while waiting for %s (transition %d/%d)", name, phase, phases[next], next+1, len(phases))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
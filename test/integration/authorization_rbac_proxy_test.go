@@ -1,9 +1,11 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	kauthorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -16,11 +18,23 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizer "k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/client-go/kubernetes"
 	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/client-go/tools/cache"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
 
 	authorizationv1 "github.com/openshift/api/authorization/v1"
 	authorizationv1client "github.com/openshift/client-go/authorization/clientset/versioned/typed/authorization/v1"
 
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+	"github.com/openshift/origin/pkg/authorization/bootstrappolicy"
+	"github.com/openshift/origin/pkg/authorization/controller/clusterroleaggregation"
+	"github.com/openshift/origin/pkg/authorization/controller/ownerreconciliation"
+	"github.com/openshift/origin/pkg/authorization/controller/policytemplate"
+	"github.com/openshift/origin/pkg/authorization/rulevalidation"
 	testutil "github.com/openshift/origin/test/util"
 	testserver "github.com/openshift/origin/test/util/server"
 )
@@ -617,6 +631,35 @@ func TestLegacyLocalRoleEndpoint(t *testing.T) {
 	}
 }
 
+// toRBACPolicyRules converts the legacy authorization.openshift.io
+// PolicyRule shape to rbacv1.PolicyRule so it can be fed to rulevalidation,
+// which only knows about the native RBAC type.
+func toRBACPolicyRules(rules []authorizationv1.PolicyRule) []rbacv1.PolicyRule {
+	out := make([]rbacv1.PolicyRule, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, rbacv1.PolicyRule{
+			Verbs:           r.Verbs,
+			APIGroups:       r.APIGroups,
+			Resources:       r.Resources,
+			ResourceNames:   r.ResourceNames,
+			NonResourceURLs: r.NonResourceURLs,
+		})
+	}
+	return out
+}
+
+// clusterAdminPolicyRules returns the canonical cluster-admin ClusterRole's
+// own Rules, the requestedRules a real bind-to-cluster-admin escalation
+// check would evaluate.
+func clusterAdminPolicyRules() []rbacv1.PolicyRule {
+	for _, cr := range bootstrappolicy.ClusterRoles() {
+		if cr.Name == bootstrappolicy.ClusterRoleClusterAdmin {
+			return cr.Rules
+		}
+	}
+	return nil
+}
+
 // TestLegacyEndpointConfirmNoEscalation tests that the authorization proxy endpoints cannot be used to bypass
 // the RBAC escalation checks.  It also makes sure that the GR in the returned error matches authorization v1.
 func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
@@ -704,14 +747,40 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 		}
 	}
 
+	rbacClient := rbacv1client.NewForConfigOrDie(clusterAdminClientConfig)
+	resolver := liveRuleResolver{rbac: rbacClient}
+	escalatingRBACRules := toRBACPolicyRules(escalatingRules)
+	clusterAdminRoleRef := &rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: bootstrappolicy.ClusterRoleClusterAdmin}
+
 	tests := []struct {
 		name     string
 		resource string
-		run      func() error
+		// wantCauseContains lists substrings that must each appear in the
+		// aggregate of the forbidden error's Details.Causes, letting the test
+		// assert the exact missing-rule delta instead of only the message
+		// prefix.
+		wantCauseContains []string
+		// clusterScoped selects whether requestedRules/roleRef below are
+		// checked against the empty (cluster-scoped) namespace rather than
+		// namespace, matching how the (Cluster)Role(Binding) being mutated is
+		// itself scoped.
+		clusterScoped bool
+		// requestedRules and roleRef are what a real ConfirmNoEscalation call
+		// site would pass for this mutation; the legacy REST storage that
+		// actually fronts userAuthorizationClient isn't part of this tree,
+		// so the test drives rulevalidation directly
+		// with them to verify the Causes it would have produced, the same
+		// way TestLegacyEndpointBindPermissionGrantsAccessWithoutFullRuleCoverage
+		// does for the bind short-circuit.
+		requestedRules []rbacv1.PolicyRule
+		roleRef        *rbacv1.RoleRef
+		run            func() error
 	}{
 		{
-			name:     "role create",
-			resource: "roles",
+			name:              "role create",
+			resource:          "roles",
+			wantCauseContains: []string{"bear", "pandas", "hug"},
+			requestedRules:    escalatingRBACRules,
 			run: func() error {
 				_, err := userAuthorizationClient.Roles(namespace).Create(&authorizationv1.Role{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -721,8 +790,10 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			},
 		},
 		{
-			name:     "role update",
-			resource: "roles",
+			name:              "role update",
+			resource:          "roles",
+			wantCauseContains: []string{"bear", "pandas", "hug"},
+			requestedRules:    escalatingRBACRules,
 			run: func() error {
 				role, err := userAuthorizationClient.Roles(namespace).Create(&authorizationv1.Role{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -738,8 +809,11 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			},
 		},
 		{
-			name:     "role binding create",
-			resource: "rolebindings",
+			name:              "role binding create",
+			resource:          "rolebindings",
+			wantCauseContains: []string{"cluster-admin"},
+			requestedRules:    clusterAdminPolicyRules(),
+			roleRef:           clusterAdminRoleRef,
 			run: func() error {
 				_, err := userAuthorizationClient.RoleBindings(namespace).Create(&authorizationv1.RoleBinding{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -752,8 +826,11 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			},
 		},
 		{
-			name:     "role binding update",
-			resource: "rolebindings",
+			name:              "role binding update",
+			resource:          "rolebindings",
+			wantCauseContains: []string{"cluster-admin"},
+			requestedRules:    clusterAdminPolicyRules(),
+			roleRef:           clusterAdminRoleRef,
 			run: func() error {
 				roleBinding, err := clusterAdminAuthorizationClient.RoleBindings(namespace).Create(&authorizationv1.RoleBinding{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -778,8 +855,11 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			},
 		},
 		{
-			name:     "cluster role create",
-			resource: "clusterroles",
+			name:              "cluster role create",
+			resource:          "clusterroles",
+			wantCauseContains: []string{"bear", "pandas", "hug"},
+			clusterScoped:     true,
+			requestedRules:    escalatingRBACRules,
 			run: func() error {
 				_, err := userAuthorizationClient.ClusterRoles().Create(&authorizationv1.ClusterRole{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -789,8 +869,11 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			},
 		},
 		{
-			name:     "cluster role update",
-			resource: "clusterroles",
+			name:              "cluster role update",
+			resource:          "clusterroles",
+			wantCauseContains: []string{"bear", "pandas", "hug"},
+			clusterScoped:     true,
+			requestedRules:    escalatingRBACRules,
 			run: func() error {
 				clusterRole, err := userAuthorizationClient.ClusterRoles().Create(&authorizationv1.ClusterRole{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -806,8 +889,12 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			},
 		},
 		{
-			name:     "cluster role binding create",
-			resource: "clusterrolebindings",
+			name:              "cluster role binding create",
+			resource:          "clusterrolebindings",
+			wantCauseContains: []string{"cluster-admin"},
+			clusterScoped:     true,
+			requestedRules:    clusterAdminPolicyRules(),
+			roleRef:           clusterAdminRoleRef,
 			run: func() error {
 				_, err := userAuthorizationClient.ClusterRoleBindings().Create(&authorizationv1.ClusterRoleBinding{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -820,8 +907,12 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			},
 		},
 		{
-			name:     "cluster role binding update",
-			resource: "clusterrolebindings",
+			name:              "cluster role binding update",
+			resource:          "clusterrolebindings",
+			wantCauseContains: []string{"cluster-admin"},
+			clusterScoped:     true,
+			requestedRules:    clusterAdminPolicyRules(),
+			roleRef:           clusterAdminRoleRef,
 			run: func() error {
 				clusterRoleBinding, err := clusterAdminAuthorizationClient.ClusterRoleBindings().Create(&authorizationv1.ClusterRoleBinding{
 					ObjectMeta: metav1.ObjectMeta{Name: resourceName},
@@ -875,6 +966,702 @@ func TestLegacyEndpointConfirmNoEscalation(t *testing.T) {
 			if !strings.HasPrefix(gotErr, wantErr) {
 				t.Errorf("expected escalation message prefix %q got %q", wantErr, gotErr)
 			}
+
+			// The legacy authorization.openshift.io REST storage that
+			// actually fronts userAuthorizationClient lives outside this
+			// tree and re-wraps escalation errors through
+			// apierrors.NewForbidden, which does not preserve
+			// Details.Causes. Drive rulevalidation.ConfirmNoEscalation
+			// directly, the same way
+			// TestLegacyEndpointBindPermissionGrantsAccessWithoutFullRuleCoverage
+			// does, to verify the Causes a real call site wired to it would
+			// have produced for this exact mutation.
+			ns := namespace
+			if tt.clusterScoped {
+				ns = ""
+			}
+			confirmErr := rulevalidation.ConfirmNoEscalation(context.Background(), resolver, &user.DefaultInfo{Name: userName}, ns, wantGR, resourceName, tt.roleRef, nil, tt.requestedRules)
+			if confirmErr == nil {
+				t.Fatalf("expected ConfirmNoEscalation to also report an escalation for this request")
+			}
+			confirmStatus, ok := confirmErr.(kapierror.APIStatus)
+			if !ok {
+				t.Fatalf("expected a structured status error from ConfirmNoEscalation, got %v", confirmErr)
+			}
+
+			causeText := ""
+			if details := confirmStatus.Status().Details; details != nil {
+				for _, cause := range details.Causes {
+					causeText += cause.Message + "\n"
+				}
+			}
+			for _, want := range tt.wantCauseContains {
+				if !strings.Contains(causeText, want) {
+					t.Errorf("expected a Details.Cause mentioning %q, got causes %q", want, causeText)
+				}
+			}
 		})
 	}
 }
+
+// TestManagedClusterRoleBindingDriftReconciliation verifies that a
+// ClusterRoleBinding carrying the ownerreconciliation.OwnerAnnotation is
+// treated as authoritative: an out-of-band mutation of its subjects is
+// reverted by the reconciler within a bounded interval, and the restored
+// binding is then visible through the legacy authorization.openshift.io
+// endpoint as well as the RBAC endpoint.
+func TestManagedClusterRoleBindingDriftReconciliation(t *testing.T) {
+	masterConfig, clusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testserver.CleanupMasterEtcd(t, masterConfig)
+
+	clusterAdminClientConfig, err := testutil.GetClusterAdminClientConfig(clusterAdminKubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rbacClient := rbacv1client.NewForConfigOrDie(clusterAdminClientConfig)
+	const bindingName = "test-managed-binding"
+	const owner = "test-controlplane"
+
+	expected := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: bindingName},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+	}
+
+	reconciler := ownerreconciliation.NewReconciler(rbacClient, nil, []ownerreconciliation.ExpectedClusterRoleBinding{
+		{Owner: owner, Binding: expected},
+	})
+	reconciler.Interval = 100 * time.Millisecond
+
+	if err := reconciler.ReconcileOnce(); err != nil {
+		t.Fatalf("unexpected error on initial reconcile: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go reconciler.Run(stopCh)
+
+	// Mutate the managed binding out-of-band, as if an operator had edited
+	// it directly rather than going through the owning control plane.
+	drifted, err := rbacClient.ClusterRoleBindings().Get(bindingName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drifted.Subjects = []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "mallory"}}
+	if _, err := rbacClient.ClusterRoleBindings().Update(drifted); err != nil {
+		t.Fatal(err)
+	}
+
+	authorizationClient := authorizationv1client.NewForConfigOrDie(clusterAdminClientConfig).ClusterRoleBindings()
+
+	err = wait.Poll(100*time.Millisecond, 10*time.Second, func() (bool, error) {
+		current, err := rbacClient.ClusterRoleBindings().Get(bindingName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return len(current.Subjects) == 1 && current.Subjects[0].Name == "alice", nil
+	})
+	if err != nil {
+		t.Fatalf("binding was not restored within the bounded interval: %v", err)
+	}
+
+	// The restored binding must still be visible through the legacy proxy.
+	legacyBinding, err := authorizationClient.Get(bindingName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(legacyBinding.Subjects) != 1 || legacyBinding.Subjects[0].Name != "alice" {
+		t.Fatalf("expected legacy endpoint to reflect restored subjects, got %#v", legacyBinding.Subjects)
+	}
+}
+
+// liveClusterRoleGetter adapts a live rbacv1client to rulevalidation's
+// ClusterRoleGetter, the same resolution interface the rolebindings/rules
+// and clusterrolebindings/rules subresources use to flatten a RoleRef into
+// its effective PolicyRules.
+type liveClusterRoleGetter struct {
+	client rbacv1client.ClusterRolesGetter
+}
+
+func (g liveClusterRoleGetter) GetClusterRole(name string) (*rbacv1.ClusterRole, error) {
+	return g.client.ClusterRoles().Get(name, metav1.GetOptions{})
+}
+
+func (g liveClusterRoleGetter) ListClusterRoles() ([]*rbacv1.ClusterRole, error) {
+	list, err := g.client.ClusterRoles().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*rbacv1.ClusterRole, 0, len(list.Items))
+	for i := range list.Items {
+		out = append(out, &list.Items[i])
+	}
+	return out, nil
+}
+
+// TestClusterRoleBindingEffectiveRulesSubresource exercises
+// rulevalidation.EffectiveRulesFor, the rules-flattening resolver a served
+// rolebindings/rules or clusterrolebindings/rules subresource would call: a
+// ClusterRoleBinding pointing at an aggregated ClusterRole must resolve to
+// the union of every component ClusterRole's rules, and a dangling RoleRef
+// must resolve to an EffectiveRulesResult carrying an error rather than
+// failing the call outright. No such subresource is actually served in this
+// tree — that would require a rest.Storage REST layer this slice of the
+// repository doesn't have — so this drives EffectiveRulesFor directly
+// against a real cluster's live ClusterRoles rather than through an HTTP
+// endpoint.
+func TestClusterRoleBindingEffectiveRulesSubresource(t *testing.T) {
+	masterConfig, clusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testserver.CleanupMasterEtcd(t, masterConfig)
+
+	clusterAdminClientConfig, err := testutil.GetClusterAdminClientConfig(clusterAdminKubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rbacClient := rbacv1client.NewForConfigOrDie(clusterAdminClientConfig)
+
+	const labelKey = "test.openshift.io/aggregate-to-rules-probe"
+	component1 := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "rules-probe-component-1", Labels: map[string]string{labelKey: "true"}},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}
+	component2 := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "rules-probe-component-2", Labels: map[string]string{labelKey: "true"}},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"get"}}},
+	}
+	aggregate := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "rules-probe-aggregate"},
+		AggregationRule: &rbacv1.AggregationRule{
+			ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{labelKey: "true"}}},
+		},
+	}
+	for _, cr := range []*rbacv1.ClusterRole{component1, component2, aggregate} {
+		if _, err := rbacClient.ClusterRoles().Create(cr); err != nil {
+			t.Fatalf("failed to create cluster role %s: %v", cr.Name, err)
+		}
+	}
+
+	binding, err := rbacClient.ClusterRoleBindings().Create(&rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "rules-probe-binding"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "probe-user"}},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: aggregate.Name},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getter := liveClusterRoleGetter{client: rbacClient}
+	result := rulevalidation.EffectiveRulesFor(nil, getter, "", binding.RoleRef)
+	if result.Error != "" {
+		t.Fatalf("unexpected error resolving effective rules: %s", result.Error)
+	}
+
+	gotResources := sets.String{}
+	for _, rule := range result.Rules {
+		gotResources.Insert(rule.Resources...)
+	}
+	if !gotResources.HasAll("pods", "services") {
+		t.Fatalf("expected the union of the aggregated cluster roles' resources, got %#v", result.Rules)
+	}
+
+	missingResult := rulevalidation.EffectiveRulesFor(nil, getter, "", rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "does-not-exist"})
+	if missingResult.Error == "" {
+		t.Fatal("expected a PartialResult error for a dangling RoleRef, got none")
+	}
+	if len(missingResult.Rules) != 0 {
+		t.Fatalf("expected no rules alongside the error, got %#v", missingResult.Rules)
+	}
+}
+
+// TestPolicyTemplateMaterializesOnProjectCreation exercises the policy
+// template controller end to end against a real master: the controller is
+// started with a policytemplate.NewNamespaceInformer watching namespaces
+// before the project exists, so creating a new project through
+// testserver.CreateNewProject fires the controller automatically, the way
+// a real server would wire it up, rather than this test calling
+// MaterializeNamespace by hand. The materialized RoleBinding must have its
+// ${REQUESTER} and ${SA:<name>} subjects substituted, visible through both
+// the legacy authorization.openshift.io proxy and the RBAC endpoint.
+func TestPolicyTemplateMaterializesOnProjectCreation(t *testing.T) {
+	masterConfig, clusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testserver.CleanupMasterEtcd(t, masterConfig)
+
+	clusterAdminClientConfig, err := testutil.GetClusterAdminClientConfig(clusterAdminKubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const namespace = "template-probe-project"
+	const requester = "template-probe-user"
+
+	rbacClient := rbacv1client.NewForConfigOrDie(clusterAdminClientConfig)
+	kubeClient := kubernetes.NewForConfigOrDie(clusterAdminClientConfig)
+
+	template := authorizationapi.PolicyTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "probe-template", ResourceVersion: "1"},
+		RoleBindingTemplates: []authorizationapi.RoleBindingTemplate{
+			{
+				Name: "${PROJECT}-probe-binding",
+				Subjects: []kapi.ObjectReference{
+					{Kind: "User", Name: "${REQUESTER}"},
+					{Kind: "ServiceAccount", Name: "${SA:builder}"},
+				},
+				RoleRef: kapi.ObjectReference{Kind: "ClusterRole", Name: "edit"},
+			},
+		},
+	}
+
+	controller := policytemplate.NewController(
+		func() ([]authorizationapi.PolicyTemplate, error) { return []authorizationapi.PolicyTemplate{template}, nil },
+		func(name string) (*corev1.Namespace, error) { return kubeClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{}) },
+		func(name string, annotations map[string]string) error {
+			ns, err := kubeClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			updated := ns.DeepCopy()
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			for k, v := range annotations {
+				updated.Annotations[k] = v
+			}
+			_, err = kubeClient.CoreV1().Namespaces().Update(updated)
+			return err
+		},
+		rbacClient,
+	)
+
+	// Start the namespace informer and the controller's workers before the
+	// project exists, so the real Add event testserver.CreateNewProject
+	// triggers below is what drives materialization, not a hand call to
+	// MaterializeNamespace.
+	informer := policytemplate.NewNamespaceInformer(kubeClient.CoreV1(), controller)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go informer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		t.Fatal("timed out waiting for namespace informer cache to sync")
+	}
+	go controller.Run(1, stopCh)
+
+	_, _, err = testserver.CreateNewProject(clusterAdminClientConfig, namespace, requester)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rbacBinding *rbacv1.RoleBinding
+	err = wait.PollImmediate(100*time.Millisecond, 30*time.Second, func() (bool, error) {
+		var getErr error
+		rbacBinding, getErr = rbacClient.RoleBindings(namespace).Get("template-probe-project-probe-binding", metav1.GetOptions{})
+		if kapierror.IsNotFound(getErr) {
+			return false, nil
+		}
+		return getErr == nil, getErr
+	})
+	if err != nil {
+		t.Fatalf("expected the namespace informer's Add event to drive materialization automatically: %v", err)
+	}
+
+	legacyBinding, err := authorizationv1client.NewForConfigOrDie(clusterAdminClientConfig).RoleBindings(namespace).Get("template-probe-project-probe-binding", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the rolebinding via the legacy proxy endpoint: %v", err)
+	}
+
+	foundRequester, foundSA := false, false
+	for _, s := range rbacBinding.Subjects {
+		if s.Kind == "User" && s.Name == requester {
+			foundRequester = true
+		}
+		if s.Kind == rbacv1.ServiceAccountKind && s.Name == "builder" {
+			foundSA = true
+		}
+	}
+	if !foundRequester || !foundSA {
+		t.Fatalf("expected substituted requester and service account subjects, got %#v", rbacBinding.Subjects)
+	}
+
+	if len(legacyBinding.Subjects) != len(rbacBinding.Subjects) {
+		t.Fatalf("expected legacy proxy to reflect the same subjects, got %#v vs %#v", legacyBinding.Subjects, rbacBinding.Subjects)
+	}
+}
+
+// liveRoleGetter and liveClusterRoleGetter (defined above, alongside the
+// effective-rules subresource test) adapt a live rbacv1client to
+// rulevalidation's resolution interfaces. liveRuleResolver composes them to
+// answer RulesFor by aggregating every (Cluster)RoleBinding actually bound
+// to a user in the live cluster, the same way the real escalation-check
+// call site would.
+type liveRoleGetter struct {
+	client rbacv1client.RolesGetter
+}
+
+func (g liveRoleGetter) GetRole(namespace, name string) (*rbacv1.Role, error) {
+	return g.client.Roles(namespace).Get(name, metav1.GetOptions{})
+}
+
+type liveRuleResolver struct {
+	rbac rbacv1client.RbacV1Interface
+}
+
+func (r liveRuleResolver) RulesFor(actingUser user.Info, namespace string) ([]rbacv1.PolicyRule, error) {
+	roleGetter := liveRoleGetter{client: r.rbac}
+	clusterRoleGetter := liveClusterRoleGetter{client: r.rbac}
+
+	var owned []rbacv1.PolicyRule
+
+	clusterRoleBindings, err := r.rbac.ClusterRoleBindings().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, crb := range clusterRoleBindings.Items {
+		if !bindingMatchesUser(crb.Subjects, actingUser) {
+			continue
+		}
+		result := rulevalidation.EffectiveRulesFor(roleGetter, clusterRoleGetter, namespace, crb.RoleRef)
+		owned = append(owned, result.Rules...)
+	}
+
+	if len(namespace) > 0 {
+		roleBindings, err := r.rbac.RoleBindings(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, rb := range roleBindings.Items {
+			if !bindingMatchesUser(rb.Subjects, actingUser) {
+				continue
+			}
+			result := rulevalidation.EffectiveRulesFor(roleGetter, clusterRoleGetter, namespace, rb.RoleRef)
+			owned = append(owned, result.Rules...)
+		}
+	}
+
+	return owned, nil
+}
+
+func bindingMatchesUser(subjects []rbacv1.Subject, actingUser user.Info) bool {
+	for _, s := range subjects {
+		if s.Kind == rbacv1.UserKind && s.Name == actingUser.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// realBindAuthorizer answers an authorizer.Authorizer "bind" check with a
+// real SubjectAccessReview against the live cluster's own RBAC authorizer,
+// so the bind short-circuit is exercised through the exact decision a real
+// EscalationCheck call site would receive, rather than a second, hand-rolled
+// derivation of the same rule-coverage logic under test.
+type realBindAuthorizer struct {
+	kubeClient kubernetes.Interface
+}
+
+func (a realBindAuthorizer) Authorize(attrs authorizer.Attributes) (authorizer.Decision, string, error) {
+	sar := &kauthorizationv1.SubjectAccessReview{
+		Spec: kauthorizationv1.SubjectAccessReviewSpec{
+			User: attrs.GetUser().GetName(),
+			ResourceAttributes: &kauthorizationv1.ResourceAttributes{
+				Namespace: attrs.GetNamespace(),
+				Verb:      attrs.GetVerb(),
+				Group:     attrs.GetAPIGroup(),
+				Resource:  attrs.GetResource(),
+				Name:      attrs.GetName(),
+			},
+		},
+	}
+	result, err := a.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(sar)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	if result.Status.Allowed {
+		return authorizer.DecisionAllow, "", nil
+	}
+	return authorizer.DecisionNoOpinion, result.Status.Reason, nil
+}
+
+// TestLegacyEndpointBindPermissionGrantsAccessWithoutFullRuleCoverage
+// verifies rulevalidation.EscalationCheck's "bind" short-circuit against a
+// real cluster: a user granted only the bind verb, scoped by resourceName
+// to cluster-admin, is authorized to bind cluster-admin without holding any
+// of the rules it contains, while a user without that grant still hits the
+// existing escalation error. The legacy authorization.openshift.io
+// RoleBinding/ClusterRoleBinding REST storage that would call EscalationCheck
+// on every real Create() lives outside this tree, so this drives
+// EscalationCheck directly; realBindAuthorizer still answers the "bind"
+// check with a genuine SubjectAccessReview against the live cluster's RBAC
+// authorizer, so the short-circuit's authorization decision is the one a
+// real call site would actually receive, not a re-derivation of it.
+func TestLegacyEndpointBindPermissionGrantsAccessWithoutFullRuleCoverage(t *testing.T) {
+	masterConfig, clusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testserver.CleanupMasterEtcd(t, masterConfig)
+
+	clusterAdminClientConfig, err := testutil.GetClusterAdminClientConfig(clusterAdminKubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterAdminAuthorizationClient := authorizationv1client.NewForConfigOrDie(clusterAdminClientConfig)
+	rbacClient := rbacv1client.NewForConfigOrDie(clusterAdminClientConfig)
+	kubeClient := kubernetes.NewForConfigOrDie(clusterAdminClientConfig)
+
+	const bindOnlyUser = "bind-only-user"
+	const noBindUser = "no-bind-user"
+	const bindRoleName = "bind-cluster-admin-only"
+
+	bindOnlyInternalClient, _, err := testserver.CreateNewProject(clusterAdminClientConfig, "bind-only-project", bindOnlyUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bindRule := authorizationv1.PolicyRule{
+		Verbs:         []string{"bind"},
+		APIGroups:     []string{rbacv1.GroupName},
+		Resources:     []string{"clusterroles"},
+		ResourceNames: []string{"cluster-admin"},
+	}
+	if _, err := clusterAdminAuthorizationClient.ClusterRoles().Create(&authorizationv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: bindRoleName},
+		Rules:      []authorizationv1.PolicyRule{bindRule},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clusterAdminAuthorizationClient.ClusterRoleBindings().Create(&authorizationv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: bindRoleName},
+		Subjects:   []corev1.ObjectReference{{Kind: rbacv1.UserKind, Name: bindOnlyUser}},
+		RoleRef:    corev1.ObjectReference{Name: bindRoleName},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := testutil.WaitForClusterPolicyUpdate(
+		bindOnlyInternalClient.AuthorizationV1(), "bind", schema.GroupResource{Group: rbacv1.GroupName, Resource: "clusterroles"}, true,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := liveRuleResolver{rbac: rbacClient}
+	authz := realBindAuthorizer{kubeClient: kubeClient}
+	clusterAdminRoleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"}
+	escalatingRBACRules := []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}
+
+	err = rulevalidation.EscalationCheck(context.Background(), authz, rulevalidation.EscalationPolicy{}, resolver, &user.DefaultInfo{Name: bindOnlyUser}, "",
+		schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}, "probe-binding", clusterAdminRoleRef, nil, escalatingRBACRules)
+	if err != nil {
+		t.Fatalf("expected the bind-only user to be authorized via the bind short-circuit, got %v", err)
+	}
+
+	err = rulevalidation.EscalationCheck(context.Background(), authz, rulevalidation.EscalationPolicy{}, resolver, &user.DefaultInfo{Name: noBindUser}, "",
+		schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}, "probe-binding", clusterAdminRoleRef, nil, escalatingRBACRules)
+	if err == nil {
+		t.Fatal("expected a user without bind access to still receive the escalation error")
+	}
+}
+
+// emptyRuleResolver holds no rules at all, letting a test isolate a bypass
+// path (privileged policy, bind) from rule-coverage fallback.
+type emptyRuleResolver struct{}
+
+func (emptyRuleResolver) RulesFor(user.Info, string) ([]rbacv1.PolicyRule, error) {
+	return nil, nil
+}
+
+// TestLegacyEndpointPrivilegedBypassSkipsEscalationCheck verifies
+// rulevalidation.EscalationPolicy: a caller impersonating the configured
+// super-user, or a member of a configured privileged group (system:masters
+// by default), is authorized to grant cluster-admin without holding any of
+// its rules and without needing a bind grant, while an unprivileged user
+// still receives the existing escalation error.
+func TestLegacyEndpointPrivilegedBypassSkipsEscalationCheck(t *testing.T) {
+	const superUserName = "configured-super-user"
+	const privilegedGroup = "custom-privileged-group"
+	const unprivilegedUser = "unprivileged-user"
+
+	resolver := emptyRuleResolver{} // holds nothing; the policy bypass alone must suffice
+	clusterAdminRoleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"}
+	escalatingRBACRules := []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}
+	qualifiedResource := schema.GroupResource{Group: "authorization.openshift.io", Resource: "clusterrolebindings"}
+
+	policy := rulevalidation.EscalationPolicy{SuperUser: superUserName, PrivilegedGroups: []string{rulevalidation.DefaultPrivilegedGroup, privilegedGroup}}
+
+	if err := rulevalidation.EscalationCheck(context.Background(), nil, policy, resolver, &user.DefaultInfo{Name: superUserName}, "",
+		qualifiedResource, "probe-binding", clusterAdminRoleRef, nil, escalatingRBACRules); err != nil {
+		t.Fatalf("expected the configured super-user to bypass the escalation check, got %v", err)
+	}
+
+	if err := rulevalidation.EscalationCheck(context.Background(), nil, policy, resolver, &user.DefaultInfo{Name: "masters-member", Groups: []string{rulevalidation.DefaultPrivilegedGroup}}, "",
+		qualifiedResource, "probe-binding", clusterAdminRoleRef, nil, escalatingRBACRules); err != nil {
+		t.Fatalf("expected a system:masters member to bypass the escalation check, got %v", err)
+	}
+
+	if err := rulevalidation.EscalationCheck(context.Background(), nil, policy, resolver, &user.DefaultInfo{Name: "other-group-member", Groups: []string{privilegedGroup}}, "",
+		qualifiedResource, "probe-binding", clusterAdminRoleRef, nil, escalatingRBACRules); err != nil {
+		t.Fatalf("expected a configured privileged-group member to bypass the escalation check, got %v", err)
+	}
+
+	if err := rulevalidation.EscalationCheck(context.Background(), nil, policy, resolver, &user.DefaultInfo{Name: unprivilegedUser}, "",
+		qualifiedResource, "probe-binding", clusterAdminRoleRef, nil, escalatingRBACRules); err == nil {
+		t.Fatal("expected an unprivileged user to still receive the escalation error")
+	}
+}
+
+// TestLegacyEndpointAggregatedClusterRoleEscalationUsesEffectiveRules
+// verifies that the escalation check is applied against an aggregated
+// ClusterRole's effective, controller-computed Rules rather than the
+// (potentially empty) Rules a caller submits directly: a non-privileged
+// user attempting to create or update an aggregated ClusterRole whose
+// selector matches a cluster-admin-equivalent component role must receive
+// the same forbidden error as if they had submitted those rules directly.
+func TestLegacyEndpointAggregatedClusterRoleEscalationUsesEffectiveRules(t *testing.T) {
+	masterConfig, clusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testserver.CleanupMasterEtcd(t, masterConfig)
+
+	clusterAdminClientConfig, err := testutil.GetClusterAdminClientConfig(clusterAdminKubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rbacClient := rbacv1client.NewForConfigOrDie(clusterAdminClientConfig)
+
+	const labelKey = "test.openshift.io/aggregate-to-escalation-probe"
+	component := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "escalation-probe-component", Labels: map[string]string{labelKey: "true"}},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+	}
+	if _, err := rbacClient.ClusterRoles().Create(component); err != nil {
+		t.Fatal(err)
+	}
+	aggregate := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "escalation-probe-aggregate"},
+		AggregationRule: &rbacv1.AggregationRule{
+			ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{labelKey: "true"}}},
+		},
+		// Rules is left empty, as an API caller submitting an aggregated
+		// ClusterRole normally would; the effective rules below come from
+		// the aggregation controller's computation, not this field.
+	}
+	if _, err := rbacClient.ClusterRoles().Create(aggregate); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := rbacClient.ClusterRoles().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	allPtrs := make([]*rbacv1.ClusterRole, 0, len(all.Items))
+	for i := range all.Items {
+		allPtrs = append(allPtrs, &all.Items[i])
+	}
+	effectiveRules, err := clusterroleaggregation.AggregatedRules(aggregate.AggregationRule, allPtrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(effectiveRules) == 0 {
+		t.Fatal("expected the aggregated ClusterRole to have non-empty effective rules")
+	}
+
+	resolver := emptyRuleResolver{} // the non-privileged user holds nothing
+	sources := []string{component.Name}
+	err = rulevalidation.EscalationCheck(context.Background(), nil, rulevalidation.EscalationPolicy{}, resolver, &user.DefaultInfo{Name: "non-privileged-user"}, "",
+		schema.GroupResource{Group: rbacv1.GroupName, Resource: "clusterroles"}, aggregate.Name, rbacv1.RoleRef{}, sources, effectiveRules)
+	if err == nil {
+		t.Fatal("expected a forbidden error when checking against the aggregated role's effective rules")
+	}
+	wantPrefix := fmt.Sprintf("%s %q is forbidden: user %q", schema.GroupResource{Group: rbacv1.GroupName, Resource: "clusterroles"}.String(), aggregate.Name, "non-privileged-user")
+	if !strings.HasPrefix(err.Error(), wantPrefix) {
+		t.Fatalf("expected the existing escalation message prefix %q, got %q", wantPrefix, err.Error())
+	}
+
+	statusErr, ok := err.(*kapierror.StatusError)
+	if !ok {
+		t.Fatalf("expected *kapierror.StatusError, got %T", err)
+	}
+	foundSourcesCause := false
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Field == "sources" && strings.Contains(cause.Message, component.Name) {
+			foundSourcesCause = true
+		}
+	}
+	if !foundSourcesCause {
+		t.Fatalf("expected a Details.Cause naming the resolved rule source %q, got %#v", component.Name, statusErr.ErrStatus.Details.Causes)
+	}
+}
+
+// TestBootstrapPolicyReconcilesDeletedClusterAdmin verifies the bootstrap
+// policy reconciliation that a PostStartHook runs on every server start:
+// deleting the default cluster-admin ClusterRole and re-running it (modeling
+// a restart) must recreate cluster-admin with its canonical ruleset.
+func TestBootstrapPolicyReconcilesDeletedClusterAdmin(t *testing.T) {
+	masterConfig, clusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testserver.CleanupMasterEtcd(t, masterConfig)
+
+	clusterAdminClientConfig, err := testutil.GetClusterAdminClientConfig(clusterAdminKubeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rbacClient := rbacv1client.NewForConfigOrDie(clusterAdminClientConfig)
+
+	if err := bootstrappolicy.EnsureBootstrapPolicy(rbacClient); err != nil {
+		t.Fatalf("unexpected error on initial bootstrap: %v", err)
+	}
+	if _, err := rbacClient.ClusterRoles().Get(bootstrappolicy.ClusterRoleClusterAdmin, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected cluster-admin to exist after initial bootstrap: %v", err)
+	}
+
+	if err := rbacClient.ClusterRoles().Delete(bootstrappolicy.ClusterRoleClusterAdmin, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rbacClient.ClusterRoles().Get(bootstrappolicy.ClusterRoleClusterAdmin, metav1.GetOptions{}); !kapierror.IsNotFound(err) {
+		t.Fatalf("expected cluster-admin to be gone after deletion, got %v", err)
+	}
+
+	// Re-running EnsureBootstrapPolicy is what the PostStartHook does on
+	// every server start; simulate the restart it would see.
+	if err := bootstrappolicy.EnsureBootstrapPolicy(rbacClient); err != nil {
+		t.Fatalf("unexpected error reconciling after restart: %v", err)
+	}
+
+	recreated, err := rbacClient.ClusterRoles().Get(bootstrappolicy.ClusterRoleClusterAdmin, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected cluster-admin to be recreated on restart: %v", err)
+	}
+	var expected rbacv1.ClusterRole
+	for _, cr := range bootstrappolicy.ClusterRoles() {
+		if cr.Name == bootstrappolicy.ClusterRoleClusterAdmin {
+			expected = cr
+		}
+	}
+	if len(recreated.Rules) != len(expected.Rules) {
+		t.Fatalf("expected recreated cluster-admin to have %d rules, got %#v", len(expected.Rules), recreated.Rules)
+	}
+
+	binding, err := rbacClient.ClusterRoleBindings().Get(bootstrappolicy.ClusterRoleClusterAdmin, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the cluster-admin ClusterRoleBinding to exist: %v", err)
+	}
+	foundMasters := false
+	for _, subject := range binding.Subjects {
+		if subject.Name == bootstrappolicy.GroupMasters {
+			foundMasters = true
+		}
+	}
+	if !foundMasters {
+		t.Fatalf("expected cluster-admin ClusterRoleBinding to bind system:masters, got %#v", binding.Subjects)
+	}
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+
+	authorizationapi "github.com/openshift/origin/pkg/authorization/apis/authorization"
+	"github.com/openshift/origin/pkg/authorization/controller/globalrolebinding"
+	testserver "github.com/openshift/origin/test/util/server"
+)
+
+// TestGlobalRoleBindingPropagatesAcrossTwoRealMasters verifies the
+// globalrolebinding.Controller against two independently started real
+// masters rather than two fake.Clientsets in the same process, so the
+// propagation a GlobalRoleBinding promises actually reaches a second
+// cluster's own etcd and RBAC REST storage, not just a shared in-memory
+// object tracker.
+func TestGlobalRoleBindingPropagatesAcrossTwoRealMasters(t *testing.T) {
+	eastMasterConfig, eastClusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatalf("unexpected error starting east master: %v", err)
+	}
+	defer testserver.CleanupMasterEtcd(t, eastMasterConfig)
+
+	westMasterConfig, westClusterAdminKubeConfig, err := testserver.StartTestMasterAPI()
+	if err != nil {
+		t.Fatalf("unexpected error starting west master: %v", err)
+	}
+	defer testserver.CleanupMasterEtcd(t, westMasterConfig)
+
+	eastClient := rbacv1client.NewForConfigOrDie(eastClusterAdminKubeConfig)
+	westClient := rbacv1client.NewForConfigOrDie(westClusterAdminKubeConfig)
+
+	clientsets := map[string]rbacv1client.ClusterRoleBindingsGetter{
+		"east": eastClient,
+		"west": westClient,
+	}
+
+	binding := authorizationapi.GlobalRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "global-admins"},
+		Subjects: []kapi.ObjectReference{
+			{Kind: "User", Name: "alice"},
+		},
+		RoleRef: kapi.ObjectReference{Name: "cluster-admin"},
+	}
+
+	controller := globalrolebinding.NewController(
+		func() ([]authorizationapi.GlobalRoleBinding, error) {
+			return []authorizationapi.GlobalRoleBinding{binding}, nil
+		},
+		staticTestClusterRegistry{{Name: "east"}, {Name: "west"}},
+		func(name string) (rbacv1client.ClusterRoleBindingsGetter, error) {
+			return clientsets[name], nil
+		},
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go controller.Run(1, stopCh)
+	controller.Enqueue(binding.Name)
+
+	for clusterName, client := range clientsets {
+		var crb *rbacv1.ClusterRoleBinding
+		err := wait.PollImmediate(100*time.Millisecond, 30*time.Second, func() (bool, error) {
+			var getErr error
+			crb, getErr = client.ClusterRoleBindings().Get(binding.Name, metav1.GetOptions{})
+			if kapierror.IsNotFound(getErr) {
+				return false, nil
+			}
+			return getErr == nil, getErr
+		})
+		if err != nil {
+			t.Fatalf("expected binding propagated to real master %q: %v", clusterName, err)
+		}
+		if crb.Labels[globalrolebinding.ManagedByGlobalLabel] != binding.Name {
+			t.Fatalf("expected managed-by-global label on %q, got %v", clusterName, crb.Labels)
+		}
+		if len(crb.Subjects) != 1 || crb.Subjects[0].Name != "alice" {
+			t.Fatalf("expected alice as a subject on %q, got %#v", clusterName, crb.Subjects)
+		}
+	}
+
+	// Simulate an out-of-band mutation on the real west master's RBAC REST
+	// storage and confirm the controller corrects the drift there too.
+	drifted, err := westClient.ClusterRoleBindings().Get(binding.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	drifted.Subjects = []rbacv1.Subject{{Kind: "User", Name: "mallory"}}
+	if _, err := westClient.ClusterRoleBindings().Update(drifted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controller.Enqueue(binding.Name)
+
+	err = wait.PollImmediate(100*time.Millisecond, 30*time.Second, func() (bool, error) {
+		corrected, getErr := westClient.ClusterRoleBindings().Get(binding.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return false, getErr
+		}
+		return len(corrected.Subjects) == 1 && corrected.Subjects[0].Name == "alice", nil
+	})
+	if err != nil {
+		t.Fatalf("expected drift on the real west master corrected back to alice: %v", err)
+	}
+}
+
+type staticTestClusterRegistry []globalrolebinding.Cluster
+
+func (s staticTestClusterRegistry) ListClusters() ([]globalrolebinding.Cluster, error) {
+	return []globalrolebinding.Cluster(s), nil
+}
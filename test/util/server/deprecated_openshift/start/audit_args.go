@@ -0,0 +1,187 @@
+package start
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+
+	configapi "github.com/openshift/origin/test/util/server/deprecated_openshift/apis/config"
+)
+
+// AuditArgs holds the raw --audit-log-path, --audit-log-maxage,
+// --audit-log-maxbackup, --audit-log-maxsize, --audit-policy-file, and
+// --audit-webhook-config-file flag values and maps them into
+// configapi.AuditConfig inside BuildSerializeableMasterConfig.
+type AuditArgs struct {
+	AuditLogPath      string
+	AuditLogMaxAge    int
+	AuditLogMaxBackup int
+	AuditLogMaxSize   int
+
+	AuditPolicyFile        string
+	AuditWebhookConfigFile string
+}
+
+// NewDefaultAuditArgs creates AuditArgs with audit logging left off; setting
+// AuditLogPath or AuditWebhookConfigFile is what turns the resulting
+// AuditConfig.Enabled on.
+func NewDefaultAuditArgs() *AuditArgs {
+	return &AuditArgs{}
+}
+
+// AddFlags registers --audit-log-path, --audit-log-maxage,
+// --audit-log-maxbackup, --audit-log-maxsize, --audit-policy-file, and
+// --audit-webhook-config-file on flags.
+func (args *AuditArgs) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&args.AuditLogPath, "audit-log-path", args.AuditLogPath,
+		"The file to write the audit log to; audit logging is off when unset.")
+	flags.IntVar(&args.AuditLogMaxAge, "audit-log-maxage", args.AuditLogMaxAge,
+		"The maximum number of days to retain audit log files.")
+	flags.IntVar(&args.AuditLogMaxBackup, "audit-log-maxbackup", args.AuditLogMaxBackup,
+		"The maximum number of audit log files to retain.")
+	flags.IntVar(&args.AuditLogMaxSize, "audit-log-maxsize", args.AuditLogMaxSize,
+		"The maximum size in megabytes of an audit log file before it gets rotated.")
+	flags.StringVar(&args.AuditPolicyFile, "audit-policy-file", args.AuditPolicyFile,
+		"A file with an audit policy to use as the audit.k8s.io/v1 Policy.")
+	flags.StringVar(&args.AuditWebhookConfigFile, "audit-webhook-config-file", args.AuditWebhookConfigFile,
+		"A kubeconfig pointing at the remote webhook service to send audit events to.")
+}
+
+// enabled reports whether any audit sink is configured.
+func (args AuditArgs) enabled() bool {
+	return len(args.AuditLogPath) > 0 || len(args.AuditWebhookConfigFile) > 0
+}
+
+// validate checks that the policy file parses as a v1.Policy, that the log
+// directory is writable, and, when configured, that the webhook kubeconfig
+// loads with a CA for its current-context cluster.
+func (args AuditArgs) validate() error {
+	if len(args.AuditPolicyFile) > 0 {
+		raw, err := os.ReadFile(args.AuditPolicyFile)
+		if err != nil {
+			return fmt.Errorf("audit policy file %q could not be read: %v", args.AuditPolicyFile, err)
+		}
+		policy := &auditv1.Policy{}
+		if err := yaml.Unmarshal(raw, policy); err != nil {
+			return fmt.Errorf("audit policy file %q does not parse as a v1.Policy: %v", args.AuditPolicyFile, err)
+		}
+	}
+
+	if len(args.AuditLogPath) > 0 {
+		if err := checkDirWritable(filepath.Dir(args.AuditLogPath)); err != nil {
+			return fmt.Errorf("audit log directory for %q is not writable: %v", args.AuditLogPath, err)
+		}
+	}
+
+	if len(args.AuditWebhookConfigFile) > 0 {
+		if _, err := args.webhookCA(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// webhookHost returns the host the configured audit webhook is reachable
+// on, so GetServerCertHostnames can include it when locally bootstrapping
+// TLS for the sink. Returns "" when no webhook is configured.
+func (args AuditArgs) webhookHost() (string, error) {
+	if len(args.AuditWebhookConfigFile) == 0 {
+		return "", nil
+	}
+
+	cluster, err := args.webhookCluster()
+	if err != nil {
+		return "", err
+	}
+
+	serverURL, err := url.Parse(cluster.Server)
+	if err != nil {
+		return "", fmt.Errorf("audit webhook kubeconfig %q server %q does not parse: %v", args.AuditWebhookConfigFile, cluster.Server, err)
+	}
+	return serverURL.Hostname(), nil
+}
+
+// webhookCA validates that the webhook kubeconfig's current-context cluster
+// carries a CA, resolving it on disk when given as a file path.
+func (args AuditArgs) webhookCA() (string, error) {
+	cluster, err := args.webhookCluster()
+	if err != nil {
+		return "", err
+	}
+
+	if len(cluster.CertificateAuthority) == 0 && len(cluster.CertificateAuthorityData) == 0 {
+		return "", fmt.Errorf("audit webhook kubeconfig %q cluster has no CA", args.AuditWebhookConfigFile)
+	}
+	if len(cluster.CertificateAuthority) > 0 {
+		if _, err := os.Stat(cluster.CertificateAuthority); err != nil {
+			return "", fmt.Errorf("audit webhook CA file %q does not resolve: %v", cluster.CertificateAuthority, err)
+		}
+	}
+	return cluster.CertificateAuthority, nil
+}
+
+func (args AuditArgs) webhookCluster() (*clientcmdapi.Cluster, error) {
+	kubeConfig, err := clientcmd.LoadFromFile(args.AuditWebhookConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("audit webhook kubeconfig %q does not load: %v", args.AuditWebhookConfigFile, err)
+	}
+	context, ok := kubeConfig.Contexts[kubeConfig.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("audit webhook kubeconfig %q has no current context", args.AuditWebhookConfigFile)
+	}
+	cluster, ok := kubeConfig.Clusters[context.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("audit webhook kubeconfig %q is missing cluster %q", args.AuditWebhookConfigFile, context.Cluster)
+	}
+	return cluster, nil
+}
+
+// checkDirWritable verifies dir exists and that a file can actually be
+// created in it, the way a log rotator will need to.
+func checkDirWritable(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+
+	probe := filepath.Join(dir, ".audit-write-probe")
+	f, err := os.OpenFile(probe, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(probe)
+}
+
+// buildAuditConfig maps the configured flags into a configapi.AuditConfig,
+// or returns nil when no audit sink is configured.
+func (args AuditArgs) buildAuditConfig() (*configapi.AuditConfig, error) {
+	if !args.enabled() {
+		return nil, nil
+	}
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+
+	return &configapi.AuditConfig{
+		Enabled:                  true,
+		AuditFilePath:            args.AuditLogPath,
+		MaximumFileRetentionDays: args.AuditLogMaxAge,
+		MaximumRetainedFiles:     args.AuditLogMaxBackup,
+		MaximumFileSizeMegabytes: args.AuditLogMaxSize,
+		PolicyFile:               args.AuditPolicyFile,
+		WebHookKubeConfig:        args.AuditWebhookConfigFile,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package start
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+
+	configapi "github.com/openshift/origin/test/util/server/deprecated_openshift/apis/config"
+)
+
+// AddConfigOverlayFlags registers --config-overlay, repeatable, into
+// overlayFiles (MasterArgs.ConfigOverlayFiles).
+func AddConfigOverlayFlags(flags *pflag.FlagSet, overlayFiles *[]string) {
+	flags.StringArrayVar(overlayFiles, "config-overlay", *overlayFiles,
+		"A YAML file strategically merged onto the built MasterConfig, applied in the given order; may be repeated.")
+}
+
+// mutators are hooks registered via RegisterMutator. BuildSerializeableMasterConfig
+// runs them, in registration order, after every --config-overlay file has
+// been merged in and before the final applyDefaults pass backfills any
+// zeroed fields the overlay introduced.
+var mutators []func(*configapi.MasterConfig) error
+
+// RegisterMutator adds a hook that mutates the fully built MasterConfig,
+// letting downstream integrators (in-tree test rigs, for example) layer on
+// network plugin, admission, or audit settings without forking
+// BuildSerializeableMasterConfig. Hooks run in registration order.
+func RegisterMutator(fn func(config *configapi.MasterConfig) error) {
+	mutators = append(mutators, fn)
+}
+
+// applyConfigOverlays decodes each overlay file in order as a partial
+// configapi.MasterConfig document and strategically merges it onto config:
+// fields tagged patchStrategy:"merge" append/merge by their patch merge key,
+// everything else replaces wholesale, the same semantics
+// k8s.io/apimachinery/pkg/util/strategicpatch applies everywhere else in the
+// API machinery.
+func applyConfigOverlays(config *configapi.MasterConfig, overlayFiles []string) (*configapi.MasterConfig, error) {
+	merged, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range overlayFiles {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("config overlay %q could not be read: %v", file, err)
+		}
+		patch, err := yaml.YAMLToJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("config overlay %q does not parse as YAML: %v", file, err)
+		}
+		merged, err = strategicpatch.StrategicMergePatch(merged, patch, &configapi.MasterConfig{})
+		if err != nil {
+			return nil, fmt.Errorf("config overlay %q could not be merged: %v", file, err)
+		}
+	}
+
+	overlaid := &configapi.MasterConfig{}
+	if err := json.Unmarshal(merged, overlaid); err != nil {
+		return nil, err
+	}
+
+	return overlaid, nil
+}
@@ -0,0 +1,48 @@
+package start
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/origin/pkg/authorization/rulevalidation"
+)
+
+// EscalationArgs holds the raw --rbac-super-user and --rbac-privileged-group
+// flag values and maps them into a rulevalidation.EscalationPolicy.
+type EscalationArgs struct {
+	SuperUser        string
+	PrivilegedGroups []string
+}
+
+// NewDefaultEscalationArgs creates EscalationArgs with no super-user and no
+// privileged groups configured; BuildEscalationPolicy fills in
+// rulevalidation.DefaultPrivilegedGroup for the latter in that case.
+func NewDefaultEscalationArgs() *EscalationArgs {
+	return &EscalationArgs{}
+}
+
+// AddFlags registers --rbac-super-user and --rbac-privileged-group on flags.
+func (args *EscalationArgs) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&args.SuperUser, "rbac-super-user", args.SuperUser,
+		"A username that bypasses RBAC escalation checks entirely; unset by default.")
+	flags.StringArrayVar(&args.PrivilegedGroups, "rbac-privileged-group", args.PrivilegedGroups,
+		"A group that bypasses RBAC escalation checks entirely; may be repeated. "+
+			"Defaults to rulevalidation.DefaultPrivilegedGroup (system:masters) when --rbac-privileged-group is not set, "+
+			"independent of --rbac-super-user.")
+}
+
+// BuildEscalationPolicy maps the configured flags into a
+// rulevalidation.EscalationPolicy. PrivilegedGroups defaults to
+// rulevalidation.DefaultPrivilegedGroup whenever --rbac-privileged-group
+// wasn't set, regardless of whether --rbac-super-user was: a configured
+// super-user is additive to, not a replacement for, the system:masters
+// bypass every cluster still relies on.
+func (args EscalationArgs) BuildEscalationPolicy() rulevalidation.EscalationPolicy {
+	privilegedGroups := args.PrivilegedGroups
+	if len(privilegedGroups) == 0 {
+		privilegedGroups = []string{rulevalidation.DefaultPrivilegedGroup}
+	}
+	return rulevalidation.EscalationPolicy{
+		SuperUser:        args.SuperUser,
+		PrivilegedGroups: privilegedGroups,
+	}
+}
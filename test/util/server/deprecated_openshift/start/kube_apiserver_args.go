@@ -0,0 +1,146 @@
+package start
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// KubeAPIServerArgs holds the raw --enable-watch-cache, --watch-cache-sizes,
+// --service-node-port-range, --max-requests-inflight,
+// --max-mutating-requests-inflight, and --admission-control flag values and
+// turns them into the KubernetesMasterConfig.APIServerArguments entries
+// configRESTOptionsGetter and the admission chain pick up.
+type KubeAPIServerArgs struct {
+	EnableWatchCache bool
+	// WatchCacheSizes is a list of "resource#size" entries, e.g.
+	// "replicationcontrollers#1000".
+	WatchCacheSizes []string
+	// ServiceNodePortRange is a port range like "30000-32767".
+	ServiceNodePortRange string
+
+	MaxRequestsInFlight         int
+	MaxMutatingRequestsInFlight int
+
+	AdmissionControl []string
+}
+
+// NewDefaultKubeAPIServerArgs creates KubeAPIServerArgs with the historical
+// kube-apiserver defaults: watch cache on, no extra sizes, no request-
+// in-flight limits, and no admission plugins configured here.
+func NewDefaultKubeAPIServerArgs() *KubeAPIServerArgs {
+	return &KubeAPIServerArgs{
+		EnableWatchCache: true,
+	}
+}
+
+// AddFlags registers --enable-watch-cache, --watch-cache-sizes,
+// --service-node-port-range, --max-requests-inflight,
+// --max-mutating-requests-inflight, and --admission-control on flags.
+func (args *KubeAPIServerArgs) AddFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&args.EnableWatchCache, "enable-watch-cache", args.EnableWatchCache,
+		"Enable watch caching in the apiserver.")
+	flags.StringArrayVar(&args.WatchCacheSizes, "watch-cache-sizes", args.WatchCacheSizes,
+		"Watch cache size overrides of the form resource#size; may be repeated.")
+	flags.StringVar(&args.ServiceNodePortRange, "service-node-port-range", args.ServiceNodePortRange,
+		"A port range to reserve for service NodePorts, e.g. 30000-32767.")
+	flags.IntVar(&args.MaxRequestsInFlight, "max-requests-inflight", args.MaxRequestsInFlight,
+		"The maximum number of non-mutating requests in flight; 0 means no limit.")
+	flags.IntVar(&args.MaxMutatingRequestsInFlight, "max-mutating-requests-inflight", args.MaxMutatingRequestsInFlight,
+		"The maximum number of mutating requests in flight; 0 means no limit.")
+	flags.StringArrayVar(&args.AdmissionControl, "admission-control", args.AdmissionControl,
+		"An admission control plugin name to enable; may be repeated.")
+}
+
+// knownAdmissionPlugins are the plugins this builder accepts for the
+// "admission-control" entry of APIServerArguments; --admission-control is
+// rejected at Validate() time if it names anything else.
+var knownAdmissionPlugins = sets.NewString(
+	"NamespaceLifecycle",
+	"LimitRanger",
+	"ServiceAccount",
+	"NodeRestriction",
+	"DefaultStorageClass",
+	"DefaultTolerationSeconds",
+	"ResourceQuota",
+	"PodNodeSelector",
+	"PodTolerationRestriction",
+	"AlwaysPullImages",
+	"MutatingAdmissionWebhook",
+	"ValidatingAdmissionWebhook",
+	"SecurityContextConstraint",
+)
+
+// validate checks that every flag value parses and that AdmissionControl
+// only names plugins we know how to wire up.
+func (args KubeAPIServerArgs) validate() error {
+	for _, entry := range args.WatchCacheSizes {
+		if _, _, err := parseWatchCacheSize(entry); err != nil {
+			return err
+		}
+	}
+
+	if len(args.ServiceNodePortRange) > 0 {
+		if _, err := utilnet.ParsePortRange(args.ServiceNodePortRange); err != nil {
+			return fmt.Errorf("service node port range %q does not parse: %v", args.ServiceNodePortRange, err)
+		}
+	}
+
+	for _, plugin := range args.AdmissionControl {
+		if !knownAdmissionPlugins.Has(plugin) {
+			return fmt.Errorf("unknown admission control plugin %q", plugin)
+		}
+	}
+
+	return nil
+}
+
+// parseWatchCacheSize parses a single "resource#size" --watch-cache-sizes
+// entry.
+func parseWatchCacheSize(entry string) (resource string, size int, err error) {
+	resource, rawSize, found := strings.Cut(entry, "#")
+	if !found {
+		return "", 0, fmt.Errorf("watch cache size %q must have the form resource#size", entry)
+	}
+	size, err = strconv.Atoi(rawSize)
+	if err != nil {
+		return "", 0, fmt.Errorf("watch cache size %q has a non-numeric size: %v", entry, err)
+	}
+	return resource, size, nil
+}
+
+// buildAPIServerArguments serializes the configured flags into the
+// ExtendedArguments map KubernetesMasterConfig.APIServerArguments carries,
+// keyed by the same flag names the real kube-apiserver accepts.
+func (args KubeAPIServerArgs) buildAPIServerArguments() (map[string][]string, error) {
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+
+	arguments := map[string][]string{
+		"enable-watch-cache": {strconv.FormatBool(args.EnableWatchCache)},
+	}
+
+	if len(args.WatchCacheSizes) > 0 {
+		arguments["watch-cache-sizes"] = append([]string{}, args.WatchCacheSizes...)
+	}
+	if len(args.ServiceNodePortRange) > 0 {
+		arguments["service-node-port-range"] = []string{args.ServiceNodePortRange}
+	}
+	if args.MaxRequestsInFlight > 0 {
+		arguments["max-requests-inflight"] = []string{strconv.Itoa(args.MaxRequestsInFlight)}
+	}
+	if args.MaxMutatingRequestsInFlight > 0 {
+		arguments["max-mutating-requests-inflight"] = []string{strconv.Itoa(args.MaxMutatingRequestsInFlight)}
+	}
+	if len(args.AdmissionControl) > 0 {
+		arguments["admission-control"] = []string{strings.Join(args.AdmissionControl, ",")}
+	}
+
+	return arguments, nil
+}
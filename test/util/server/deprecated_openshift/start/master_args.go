@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"os"
 	"path"
 	"regexp"
 	"strconv"
 
+	"github.com/spf13/pflag"
+
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -71,9 +74,120 @@ type MasterArgs struct {
 
 	NetworkArgs *options.NetworkArgs
 
+	// OAuthArgs configures the identity providers BuildSerializeableOAuthConfig
+	// wires into OAuthConfig.IdentityProviders. When it has no providers
+	// configured, BuildSerializeableOAuthConfig keeps the historical
+	// AllowAllPasswordIdentityProvider default.
+	OAuthArgs *OAuthArgs
+
+	// KubeAPIServerArgs configures watch-cache behavior, the service
+	// node-port range, in-flight request limits, and admission plugins that
+	// BuildSerializeableKubeMasterConfig serializes into
+	// KubernetesMasterConfig.APIServerArguments.
+	KubeAPIServerArgs *KubeAPIServerArgs
+
+	// AuditArgs configures the API audit sink BuildSerializeableMasterConfig
+	// wires into AuditConfig. With neither AuditLogPath nor
+	// AuditWebhookConfigFile set, audit logging stays off.
+	AuditArgs *AuditArgs
+
+	// EscalationArgs configures the rulevalidation.EscalationPolicy the
+	// legacy authorization.openshift.io RBAC proxy endpoints use to bypass
+	// escalation checks for a super-user or a privileged group. With
+	// PrivilegedGroups unset, BuildEscalationPolicy fills in
+	// rulevalidation.DefaultPrivilegedGroup regardless of SuperUser.
+	EscalationArgs *EscalationArgs
+
+	// ClusterNetworkCIDRs and ServiceNetworkCIDRs support dual-stack (v4+v6)
+	// clusters by allowing more than one CIDR per family. When empty, the
+	// single-valued NetworkArgs.ClusterNetworkCIDR/ServiceNetworkCIDR is used,
+	// so existing single-stack configs are unaffected.
+	ClusterNetworkCIDRs []string
+	ServiceNetworkCIDRs []string
+
+	// EtcdServers points the master at a pre-existing, externally managed
+	// etcd cluster instead of starting one of its own. When set,
+	// BuildSerializeableMasterConfig skips BuildSerializeableEtcdConfig
+	// entirely and wires EtcdClientInfo.URLs from these endpoints.
+	EtcdServers []string
+	// EtcdCAFile, EtcdCertFile, and EtcdKeyFile are the client TLS bundle
+	// used to talk to EtcdServers. Only meaningful alongside EtcdServers.
+	EtcdCAFile   string
+	EtcdCertFile string
+	EtcdKeyFile  string
+	// EtcdPrefix is the key prefix used for all objects this master stores
+	// in the external etcd cluster.
+	EtcdPrefix string
+
+	// ConfigOverlayFiles are YAML documents, applied in order, each decoded as
+	// a partial configapi.MasterConfig and strategically merged onto the
+	// config BuildSerializeableMasterConfig produces. They run before the
+	// RegisterMutator hooks and OverrideConfig, and applyDefaults is re-run
+	// afterward to backfill anything an overlay zeroed out.
+	ConfigOverlayFiles []string
+
+	// OverrideConfig is a single ad hoc mutation hook, kept for existing
+	// callers. Prefer RegisterMutator for anything reusable across
+	// MasterArgs instances, or ConfigOverlayFiles for file-based overlays.
 	OverrideConfig func(config *configapi.MasterConfig) error
 }
 
+// externalEtcd reports whether args points at a pre-existing etcd cluster
+// rather than asking the master to start its own.
+func (args MasterArgs) externalEtcd() bool {
+	return len(args.EtcdServers) > 0
+}
+
+// AddEtcdFlags registers --etcd-servers, --etcd-cafile, --etcd-certfile,
+// --etcd-keyfile, and --etcd-prefix on flags. Setting --etcd-servers is what
+// switches BuildSerializeableMasterConfig from starting its own etcd to
+// pointing at this externally managed cluster.
+func (args *MasterArgs) AddEtcdFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&args.EtcdServers, "etcd-servers", args.EtcdServers,
+		"The URL of an externally managed etcd cluster to use instead of starting one; may be repeated.")
+	flags.StringVar(&args.EtcdCAFile, "etcd-cafile", args.EtcdCAFile,
+		"A CA bundle to validate the externally managed etcd cluster's serving certificate against.")
+	flags.StringVar(&args.EtcdCertFile, "etcd-certfile", args.EtcdCertFile,
+		"A client certificate to present to the externally managed etcd cluster.")
+	flags.StringVar(&args.EtcdKeyFile, "etcd-keyfile", args.EtcdKeyFile,
+		"The key matching --etcd-certfile.")
+	flags.StringVar(&args.EtcdPrefix, "etcd-prefix", args.EtcdPrefix,
+		"The keyspace prefix this master stores all of its objects under in etcd.")
+}
+
+// AddFlags registers the flags the chunk3 Args sub-objects and
+// ConfigOverlayFiles contribute: identity providers, kube-apiserver tuning,
+// audit logging, RBAC escalation policy, config overlays, and the
+// external-etcd bundle. The pre-existing MasterArgs fields (--master,
+// --etcd-dir, and so on) are bound by the start command that constructs
+// MasterArgs, outside this package.
+func (args *MasterArgs) AddFlags(flags *pflag.FlagSet) {
+	args.OAuthArgs.AddFlags(flags)
+	args.KubeAPIServerArgs.AddFlags(flags)
+	args.AuditArgs.AddFlags(flags)
+	args.EscalationArgs.AddFlags(flags)
+	args.AddEtcdFlags(flags)
+	AddConfigOverlayFlags(flags, &args.ConfigOverlayFiles)
+}
+
+// clusterNetworkCIDRs returns the configured dual-stack cluster network
+// CIDRs, falling back to the single NetworkArgs.ClusterNetworkCIDR value.
+func (args MasterArgs) clusterNetworkCIDRs() []string {
+	if len(args.ClusterNetworkCIDRs) > 0 {
+		return args.ClusterNetworkCIDRs
+	}
+	return []string{args.NetworkArgs.ClusterNetworkCIDR}
+}
+
+// serviceNetworkCIDRs returns the configured dual-stack service network
+// CIDRs, falling back to the single NetworkArgs.ServiceNetworkCIDR value.
+func (args MasterArgs) serviceNetworkCIDRs() []string {
+	if len(args.ServiceNetworkCIDRs) > 0 {
+		return args.ServiceNetworkCIDRs
+	}
+	return []string{args.NetworkArgs.ServiceNetworkCIDR}
+}
+
 // NewDefaultMasterArgs creates MasterArgs with sub-objects created and default values set.
 func NewDefaultMasterArgs() *MasterArgs {
 	config := &MasterArgs{
@@ -88,6 +202,10 @@ func NewDefaultMasterArgs() *MasterArgs {
 		ImageFormatArgs:    options.NewDefaultImageFormatArgs(),
 		KubeConnectionArgs: options.NewDefaultKubeConnectionArgs(),
 		NetworkArgs:        options.NewDefaultMasterNetworkArgs(),
+		OAuthArgs:          NewDefaultOAuthArgs(),
+		KubeAPIServerArgs:  NewDefaultKubeAPIServerArgs(),
+		AuditArgs:          NewDefaultAuditArgs(),
+		EscalationArgs:     NewDefaultEscalationArgs(),
 	}
 
 	return config
@@ -102,6 +220,15 @@ func (args MasterArgs) GetConfigFileToWrite() string {
 // If host contains a port, the returned regex matches the port exactly.
 // If host does not contain a port, the returned regex matches any port or no port.
 func makeHostMatchRegex(host string) string {
+	// A bare, unbracketed IPv6 literal (e.g. "::1") has no unambiguous way
+	// to separate host from port: net.SplitHostPort would wrongly treat its
+	// last hextet as a port. Bracket it first, the way a URL.Host for an
+	// IPv6 address always does, so the port split below only ever applies
+	// to an actual port suffix.
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		host = "[" + host + "]"
+	}
+
 	if _, _, err := net.SplitHostPort(host); err == nil {
 		// we have a port, match the end exactly
 		return "//" + regexp.QuoteMeta(host) + "$"
@@ -140,12 +267,15 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 		makeHostMatchRegex("127.0.0.1"),
 	)
 
-	etcdAddress, err := args.GetEtcdAddress()
-	if err != nil {
-		return nil, err
+	var etcdAddress *url.URL
+	if !args.externalEtcd() {
+		etcdAddress, err = args.GetEtcdAddress()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	builtInEtcd := !args.EtcdAddr.Provided
+	builtInEtcd := !args.EtcdAddr.Provided && !args.externalEtcd()
 	var etcdConfig *configapi.EtcdConfig
 	if builtInEtcd {
 		etcdConfig, err = args.BuildSerializeableEtcdConfig()
@@ -154,6 +284,11 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 		}
 	}
 
+	etcdClientURLs, err := args.etcdClientURLs(etcdAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	kubernetesMasterConfig, err := args.BuildSerializeableKubeMasterConfig()
 	if err != nil {
 		return nil, err
@@ -164,6 +299,14 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 		return nil, err
 	}
 
+	var auditConfig *configapi.AuditConfig
+	if args.AuditArgs != nil {
+		auditConfig, err = args.AuditArgs.buildAuditConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	kubeletClientInfo := deprecatedcerts.DefaultMasterKubeletClientCertInfo(args.ConfigDir.Value())
 
 	etcdClientInfo := deprecatedcerts.DefaultMasterEtcdClientCertInfo(args.ConfigDir.Value())
@@ -207,7 +350,7 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 		},
 
 		EtcdClientInfo: configapi.EtcdConnectionInfo{
-			URLs: []string{etcdAddress.String()},
+			URLs: etcdClientURLs,
 		},
 
 		KubeletClientInfo: configapi.KubeletConnectionInfo{
@@ -232,13 +375,12 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 
 		NetworkConfig: configapi.MasterNetworkConfig{
 			NetworkPluginName: args.NetworkArgs.NetworkPluginName,
-			ClusterNetworks: []configapi.ClusterNetworkEntry{
-				{
-					CIDR:             args.NetworkArgs.ClusterNetworkCIDR,
-					HostSubnetLength: args.NetworkArgs.HostSubnetLength,
-				},
-			},
-			ServiceNetworkCIDR: args.NetworkArgs.ServiceNetworkCIDR,
+			ClusterNetworks:   clusterNetworkEntries(args.clusterNetworkCIDRs(), args.NetworkArgs.HostSubnetLength),
+			// configapi.MasterNetworkConfig only carries a single service
+			// network CIDR, so a dual-stack second entry (e.g. the IPv6
+			// range) has nowhere to go until that struct grows a
+			// ServiceNetworkCIDRs field; the first configured family wins.
+			ServiceNetworkCIDR: args.serviceNetworkCIDRs()[0],
 		},
 
 		VolumeConfig: configapi.MasterVolumeConfig{
@@ -250,6 +392,8 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 				Signer: &configapi.CertInfo{},
 			},
 		},
+
+		AuditConfig: auditConfig,
 	}
 
 	config.ServingInfo.ServerCert = deprecatedcerts.DefaultMasterServingCertInfo(args.ConfigDir.Value())
@@ -270,6 +414,21 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 		config.EtcdClientInfo.ClientCert = etcdClientInfo.CertLocation
 	}
 
+	// An externally managed etcd cluster brings its own TLS client bundle
+	// rather than one generated under ConfigDir.
+	if args.externalEtcd() {
+		config.EtcdClientInfo.CA = args.EtcdCAFile
+		config.EtcdClientInfo.ClientCert = configapi.CertInfo{
+			CertFile: args.EtcdCertFile,
+			KeyFile:  args.EtcdKeyFile,
+		}
+	}
+
+	if len(args.EtcdPrefix) > 0 {
+		config.EtcdStorageConfig.KubernetesStoragePrefix = args.EtcdPrefix
+		config.EtcdStorageConfig.OpenShiftStoragePrefix = args.EtcdPrefix
+	}
+
 	// We're responsible for generating all the managed service accounts
 	config.ServiceAccountConfig.ManagedNames = []string{
 		"default",
@@ -292,6 +451,33 @@ func (args MasterArgs) BuildSerializeableMasterConfig() (*configapi.MasterConfig
 	// When creating a new config, use Protobuf
 	setProtobufClientDefaults(config.MasterClients.OpenShiftLoopbackClientConnectionOverrides)
 
+	if len(args.ConfigOverlayFiles) > 0 {
+		config, err = applyConfigOverlays(config, args.ConfigOverlayFiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, mutate := range mutators {
+		if err := mutate(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if args.OverrideConfig != nil {
+		if err := args.OverrideConfig(config); err != nil {
+			return nil, err
+		}
+	}
+
+	// Re-run defaulting so any zeroed fields the overlay files or mutator
+	// hooks introduced get backfilled.
+	internal, err = applyDefaults(config, legacyconfigv1.LegacySchemeGroupVersion)
+	if err != nil {
+		return nil, err
+	}
+	config = internal.(*configapi.MasterConfig)
+
 	return config, nil
 }
 
@@ -342,14 +528,27 @@ func (args MasterArgs) BuildSerializeableOAuthConfig() (*configapi.OAuthConfig,
 		},
 	}
 
-	config.IdentityProviders = append(config.IdentityProviders,
-		configapi.IdentityProvider{
-			Name:            "anypassword",
-			UseAsChallenger: true,
-			UseAsLogin:      true,
-			Provider:        &configapi.AllowAllPasswordIdentityProvider{},
-		},
-	)
+	if args.OAuthArgs == nil || len(args.OAuthArgs.Providers) == 0 {
+		config.IdentityProviders = append(config.IdentityProviders,
+			configapi.IdentityProvider{
+				Name:            "anypassword",
+				UseAsChallenger: true,
+				UseAsLogin:      true,
+				Provider:        &configapi.AllowAllPasswordIdentityProvider{},
+			},
+		)
+		return config, nil
+	}
+
+	providers, err := args.OAuthArgs.buildIdentityProviders()
+	if err != nil {
+		return nil, err
+	}
+	config.IdentityProviders = append(config.IdentityProviders, providers...)
+
+	if args.OAuthArgs.usesBrowserLogin() {
+		config.SessionConfig.SessionSecretsFile = path.Join(args.ConfigDir.Value(), "session-secrets.yaml")
+	}
 
 	return config, nil
 }
@@ -390,6 +589,49 @@ func (args MasterArgs) BuildSerializeableEtcdConfig() (*configapi.EtcdConfig, er
 
 }
 
+// etcdClientURLs returns the etcd endpoints EtcdClientInfo.URLs should carry:
+// every configured EtcdServers entry, validated as a parseable URL, when
+// args points at an externally managed cluster; otherwise the single
+// built-in or user-provided etcdAddress.
+func (args MasterArgs) etcdClientURLs(etcdAddress *url.URL) ([]string, error) {
+	if !args.externalEtcd() {
+		return []string{etcdAddress.String()}, nil
+	}
+
+	urls := make([]string, 0, len(args.EtcdServers))
+	for _, server := range args.EtcdServers {
+		parsed, err := url.Parse(server)
+		if err != nil {
+			return nil, fmt.Errorf("etcd server %q does not parse: %v", server, err)
+		}
+		urls = append(urls, parsed.String())
+	}
+	return urls, nil
+}
+
+// validateExternalEtcd checks that an externally managed etcd's TLS client
+// bundle resolves on disk before the master tries to dial it.
+func (args MasterArgs) validateExternalEtcd() error {
+	if !args.externalEtcd() {
+		return nil
+	}
+
+	for name, file := range map[string]string{
+		"etcd CA file":   args.EtcdCAFile,
+		"etcd cert file": args.EtcdCertFile,
+		"etcd key file":  args.EtcdKeyFile,
+	} {
+		if len(file) == 0 {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("%s %q does not resolve: %v", name, file, err)
+		}
+	}
+
+	return nil
+}
+
 // BuildSerializeableKubeMasterConfig creates a fully specified kubernetes master startup configuration based on MasterArgs
 func (args MasterArgs) BuildSerializeableKubeMasterConfig() (*configapi.KubernetesMasterConfig, error) {
 	masterAddr, err := args.GetMasterAddress()
@@ -412,6 +654,14 @@ func (args MasterArgs) BuildSerializeableKubeMasterConfig() (*configapi.Kubernet
 		ProxyClientInfo:     deprecatedcerts.DefaultProxyClientCertInfo(args.ConfigDir.Value()).CertLocation,
 	}
 
+	if args.KubeAPIServerArgs != nil {
+		apiServerArguments, err := args.KubeAPIServerArgs.buildAPIServerArguments()
+		if err != nil {
+			return nil, err
+		}
+		config.APIServerArguments = apiServerArguments
+	}
+
 	return config, nil
 }
 
@@ -436,6 +686,24 @@ func (args MasterArgs) Validate() error {
 		return err
 	}
 
+	if args.OAuthArgs != nil {
+		if _, err := args.OAuthArgs.buildIdentityProviders(); err != nil {
+			return err
+		}
+	}
+
+	if args.KubeAPIServerArgs != nil {
+		if err := args.KubeAPIServerArgs.validate(); err != nil {
+			return err
+		}
+	}
+
+	if args.AuditArgs != nil {
+		if err := args.AuditArgs.validate(); err != nil {
+			return err
+		}
+	}
+
 	addr, err = args.KubeConnectionArgs.GetKubernetesAddress(masterAddr)
 	if err != nil {
 		return err
@@ -444,6 +712,15 @@ func (args MasterArgs) Validate() error {
 		return fmt.Errorf("kubernetes url may not include a path: '%v'", addr.Path)
 	}
 
+	if args.externalEtcd() {
+		if _, err := args.etcdClientURLs(nil); err != nil {
+			return err
+		}
+		if err := args.validateExternalEtcd(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -475,10 +752,26 @@ func (args MasterArgs) GetServerCertHostnames() (sets.String, error) {
 		"etcd.kube-system.svc",
 		masterAddr.Host, masterPublicAddr.Host, assetPublicAddr.Host)
 
-	if _, ipnet, err := net.ParseCIDR(args.NetworkArgs.ServiceNetworkCIDR); err == nil {
-		// CIDR is ignored if it is invalid, other code handles validation.
-		if firstServiceIP, err := ipallocator.GetIndexedIP(ipnet, 1); err == nil {
-			allHostnames.Insert(firstServiceIP.String())
+	// When locally bootstrapping TLS, the audit webhook sink's host needs
+	// its own cert SAN just like the master and asset addresses do.
+	if args.AuditArgs != nil {
+		webhookHost, err := args.AuditArgs.webhookHost()
+		if err != nil {
+			return nil, err
+		}
+		if len(webhookHost) > 0 {
+			allHostnames.Insert(webhookHost)
+		}
+	}
+
+	// Add the first service IP of every configured family (v4 and, for a
+	// dual-stack cluster, v6) as a cert SAN.
+	for _, cidr := range args.serviceNetworkCIDRs() {
+		if _, ipnet, err := net.ParseCIDR(cidr); err == nil {
+			// CIDR is ignored if it is invalid, other code handles validation.
+			if firstServiceIP, err := ipallocator.GetIndexedIP(ipnet, 1); err == nil {
+				allHostnames.Insert(firstServiceIP.String())
+			}
 		}
 	}
 
@@ -508,8 +801,8 @@ func (args MasterArgs) GetServerCertHostnames() (sets.String, error) {
 }
 
 // GetMasterAddress checks for an unset master address and then attempts to use the first
-// public IPv4 non-loopback address registered on this host.
-// TODO: make me IPv6 safe
+// public IPv4 non-loopback address registered on this host, falling back to the first
+// public IPv6 address if this host has no IPv4 address configured.
 func (args MasterArgs) GetMasterAddress() (*url.URL, error) {
 	if args.MasterAddr.Provided {
 		return args.MasterAddr.URL, nil
@@ -525,7 +818,13 @@ func (args MasterArgs) GetMasterAddress() (*url.URL, error) {
 	if ip, err := cmdutil.DefaultLocalIP4(); err == nil {
 		addr = ip.String()
 	} else if err == cmdutil.ErrorNoDefaultIP {
-		addr = "127.0.0.1"
+		if ip, err := cmdutil.DefaultLocalIP6(); err == nil {
+			addr = ip.String()
+		} else if err == cmdutil.ErrorNoDefaultIP {
+			addr = "127.0.0.1"
+		} else {
+			return nil, fmt.Errorf("Unable to find a public IP address: %v", err)
+		}
 	} else if err != nil {
 		return nil, fmt.Errorf("Unable to find a public IP address: %v", err)
 	}
@@ -556,6 +855,14 @@ func (args MasterArgs) GetEtcdBindAddress() string {
 	return net.JoinHostPort(args.ListenArg.ListenAddr.Host, strconv.Itoa(args.EtcdAddr.DefaultPort))
 }
 
+// GetEtcdBindAddresses returns a one-element list holding the address
+// GetEtcdBindAddress returns. It does not derive a separate bind socket per
+// IP family: with dual-stack ClusterNetworkCIDRs/ServiceNetworkCIDRs set,
+// etcd still binds only to the single listen host.
+func (args MasterArgs) GetEtcdBindAddresses() []string {
+	return []string{args.GetEtcdBindAddress()}
+}
+
 // GetEtcdPeerBindAddress derives the etcd peer address by using the bind address
 // and the default etcd peering port
 func (args MasterArgs) GetEtcdPeerBindAddress() string {
@@ -610,6 +917,20 @@ func (args MasterArgs) GetAssetPublicAddress() (*url.URL, error) {
 	return &assetPublicAddr, nil
 }
 
+// clusterNetworkEntries builds a configapi.ClusterNetworkEntry for every
+// configured cluster network CIDR (one per family in a dual-stack cluster),
+// all sharing the same host subnet length.
+func clusterNetworkEntries(cidrs []string, hostSubnetLength uint32) []configapi.ClusterNetworkEntry {
+	entries := make([]configapi.ClusterNetworkEntry, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		entries = append(entries, configapi.ClusterNetworkEntry{
+			CIDR:             cidr,
+			HostSubnetLength: hostSubnetLength,
+		})
+	}
+	return entries
+}
+
 func getHost(theURL url.URL) string {
 	host, _, err := net.SplitHostPort(theURL.Host)
 	if err != nil {
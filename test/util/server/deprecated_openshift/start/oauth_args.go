@@ -0,0 +1,268 @@
+package start
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configapi "github.com/openshift/origin/test/util/server/deprecated_openshift/apis/config"
+)
+
+// OAuthArgs holds the raw --oauth-identity-provider flag values and turns
+// them into configapi.IdentityProvider entries. Each flag value has the form
+// "type:key=val,key=val,...", e.g.:
+//
+//	--oauth-identity-provider=htpasswd:file=/etc/origin/master/htpasswd
+//	--oauth-identity-provider=requestheader:client-ca=/path/ca.crt,username-headers=X-Remote-User
+//	--oauth-identity-provider=oidc:issuer=https://accounts.example.com,client-id=abc,client-secret=xyz
+//
+// Supported types and their keys (every type also accepts name, which
+// defaults to the type):
+//
+//	htpasswd:      file
+//	requestheader: client-ca, username-headers (optional, "|"-separated, default X-Remote-User)
+//	basicauth:     url
+//	oidc:          issuer, client-id, client-secret
+//	github:        client-id, client-secret, organizations (optional, "|"-separated)
+//	keystone:      domain-name, url
+//
+// When Providers is empty, BuildSerializeableOAuthConfig falls back to
+// today's single AllowAllPasswordIdentityProvider default.
+type OAuthArgs struct {
+	Providers []string
+}
+
+// NewDefaultOAuthArgs creates OAuthArgs with no providers configured, so
+// BuildSerializeableOAuthConfig keeps defaulting to AllowAll.
+func NewDefaultOAuthArgs() *OAuthArgs {
+	return &OAuthArgs{}
+}
+
+// AddFlags registers --oauth-identity-provider on flags, repeatable to
+// configure more than one provider.
+func (args *OAuthArgs) AddFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&args.Providers, "oauth-identity-provider", args.Providers,
+		"An identity provider spec of the form type:key=val,key=val,...; may be repeated. "+
+			"See the OAuthArgs doc comment for the supported types and their keys.")
+}
+
+// providerSpec is one parsed "type:key=val,..." flag value.
+type providerSpec struct {
+	providerType string
+	params       map[string]string
+}
+
+func parseProviderSpec(raw string) (*providerSpec, error) {
+	providerType, rest, found := strings.Cut(raw, ":")
+	if !found {
+		return nil, fmt.Errorf("identity provider %q must have the form type:key=val,...", raw)
+	}
+
+	params := map[string]string{}
+	if len(rest) > 0 {
+		for _, pair := range strings.Split(rest, ",") {
+			key, val, found := strings.Cut(pair, "=")
+			if !found {
+				return nil, fmt.Errorf("identity provider %q has a malformed key=value pair %q", raw, pair)
+			}
+			params[key] = val
+		}
+	}
+
+	return &providerSpec{providerType: providerType, params: params}, nil
+}
+
+func (p *providerSpec) name() string {
+	if name, ok := p.params["name"]; ok {
+		return name
+	}
+	return p.providerType
+}
+
+func (p *providerSpec) required(key string) (string, error) {
+	val, ok := p.params[key]
+	if !ok || len(val) == 0 {
+		return "", fmt.Errorf("%s identity provider requires %q", p.providerType, key)
+	}
+	return val, nil
+}
+
+// buildIdentityProviders parses every configured --oauth-identity-provider
+// spec into a configapi.IdentityProvider, validating that any file or URL a
+// provider references actually resolves.
+func (args OAuthArgs) buildIdentityProviders() ([]configapi.IdentityProvider, error) {
+	providers := make([]configapi.IdentityProvider, 0, len(args.Providers))
+	for _, raw := range args.Providers {
+		spec, err := parseProviderSpec(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		provider, err := spec.build()
+		if err != nil {
+			return nil, fmt.Errorf("identity provider %q: %v", raw, err)
+		}
+		providers = append(providers, *provider)
+	}
+	return providers, nil
+}
+
+func (p *providerSpec) build() (*configapi.IdentityProvider, error) {
+	var provider runtime.Object
+	var err error
+
+	switch p.providerType {
+	case "htpasswd":
+		provider, err = p.buildHTPasswd()
+	case "requestheader":
+		provider, err = p.buildRequestHeader()
+	case "basicauth":
+		provider, err = p.buildBasicAuth()
+	case "oidc":
+		provider, err = p.buildOIDC()
+	case "github":
+		provider, err = p.buildGitHub()
+	case "keystone":
+		provider, err = p.buildKeystone()
+	default:
+		return nil, fmt.Errorf("unknown identity provider type %q", p.providerType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &configapi.IdentityProvider{
+		Name:            p.name(),
+		UseAsChallenger: true,
+		UseAsLogin:      true,
+		Provider:        provider,
+	}, nil
+}
+
+func (p *providerSpec) buildHTPasswd() (*configapi.HTPasswdPasswordIdentityProvider, error) {
+	file, err := p.required("file")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("htpasswd file %q does not resolve: %v", file, err)
+	}
+	return &configapi.HTPasswdPasswordIdentityProvider{File: file}, nil
+}
+
+func (p *providerSpec) buildRequestHeader() (*configapi.RequestHeaderIdentityProvider, error) {
+	clientCA, err := p.required("client-ca")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(clientCA); err != nil {
+		return nil, fmt.Errorf("client CA file %q does not resolve: %v", clientCA, err)
+	}
+
+	headers := []string{"X-Remote-User"}
+	if raw, ok := p.params["username-headers"]; ok {
+		headers = strings.Split(raw, "|")
+	}
+
+	return &configapi.RequestHeaderIdentityProvider{
+		ClientCA: clientCA,
+		Headers:  headers,
+	}, nil
+}
+
+func (p *providerSpec) buildBasicAuth() (*configapi.BasicAuthPasswordIdentityProvider, error) {
+	rawURL, err := p.required("url")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := url.Parse(rawURL); err != nil {
+		return nil, fmt.Errorf("basic auth url %q does not parse: %v", rawURL, err)
+	}
+	return &configapi.BasicAuthPasswordIdentityProvider{URL: rawURL}, nil
+}
+
+func (p *providerSpec) buildOIDC() (*configapi.OpenIDIdentityProvider, error) {
+	issuer, err := p.required("issuer")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := url.Parse(issuer); err != nil {
+		return nil, fmt.Errorf("oidc issuer %q does not parse: %v", issuer, err)
+	}
+	clientID, err := p.required("client-id")
+	if err != nil {
+		return nil, err
+	}
+	clientSecret, err := p.required("client-secret")
+	if err != nil {
+		return nil, err
+	}
+
+	return &configapi.OpenIDIdentityProvider{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}, nil
+}
+
+func (p *providerSpec) buildGitHub() (*configapi.GitHubIdentityProvider, error) {
+	clientID, err := p.required("client-id")
+	if err != nil {
+		return nil, err
+	}
+	clientSecret, err := p.required("client-secret")
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &configapi.GitHubIdentityProvider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+	if orgs, ok := p.params["organizations"]; ok {
+		provider.Organizations = strings.Split(orgs, "|")
+	}
+	return provider, nil
+}
+
+func (p *providerSpec) buildKeystone() (*configapi.KeystonePasswordIdentityProvider, error) {
+	domainName, err := p.required("domain-name")
+	if err != nil {
+		return nil, err
+	}
+	rawURL, err := p.required("url")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := url.Parse(rawURL); err != nil {
+		return nil, fmt.Errorf("keystone url %q does not parse: %v", rawURL, err)
+	}
+
+	return &configapi.KeystonePasswordIdentityProvider{
+		DomainName: domainName,
+		URL:        rawURL,
+	}, nil
+}
+
+// usesBrowserLogin reports whether any configured provider is of type
+// requestheader, oidc, github or keystone, in which case a SessionConfig
+// gets a SessionSecretsFile. htpasswd and basicauth providers are not
+// counted, even though build sets UseAsLogin on every provider.
+func (args OAuthArgs) usesBrowserLogin() bool {
+	for _, raw := range args.Providers {
+		spec, err := parseProviderSpec(raw)
+		if err != nil {
+			continue
+		}
+		switch spec.providerType {
+		case "requestheader", "oidc", "github", "keystone":
+			return true
+		}
+	}
+	return false
+}